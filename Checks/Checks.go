@@ -2,58 +2,135 @@ package checks
 
 import (
 	Constants "Detective/Constants"
+	Logger "Detective/Logger"
 	Utils "Detective/Utils"
 	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"math"
+	"net"
 	"net/http"
+	neturl "net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/Masterminds/semver/v3"
+	"helm.sh/helm/v3/pkg/release"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/version"
 	"k8s.io/client-go/kubernetes"
 )
 
+const kubeSystemNamespace = "kube-system"
+
+// Status is the explicit outcome of a check, so a skipped check is never mistaken for a pass.
+type Status string
+
 const (
-	kubeSystemNamespace = "kube-system"
-	helmChart           = "ostore-1.5.0"
+	StatusPass Status = "PASS"
+	StatusFail Status = "FAIL"
+	StatusWarn Status = "WARN"
+	StatusSkip Status = "SKIP"
+	// StatusCancelled marks a check that never finished because the run was interrupted.
+	StatusCancelled Status = "CANCELLED"
 )
 
-// ParseJSONString takes a JSON string and unmarshals it into a generic Go data structure.
-// It returns an interface{} which can be a map[string]interface{} (for JSON objects)
-// or a []interface{} (for JSON arrays), along with an error.
+// CheckResult is the outcome of a single health check. OK carries the
+// pass/fail signal while Detail holds the human-readable explanation.
+// Status refines OK into PASS/FAIL/WARN/SKIP; when a check leaves it empty
+// it is derived from OK. Duration is filled in by the runner.
+type CheckResult struct {
+	Name     string
+	OK       bool
+	Status   Status
+	Detail   string
+	Duration time.Duration
+	// Data carries structured findings for machine-readable output.
+	Data map[string]interface{}
+	// Critical marks a check whose failure can mean data loss or an unavailable cluster.
+	Critical bool
+	// Err is the underlying error when the check couldn't get an answer at all, e.g. an unreachable
+	// endpoint, as opposed to an answer showing the cluster unhealthy.
+	Err error
+	// State holds what the check observed for the state file. The runner merges it into the run's state
+	// only when the check finished in time, so an abandoned check can't change the state being saved.
+	State *Utils.State
+}
+
+// Outcome returns the result's status, deriving PASS or FAIL from OK when the check didn't set one.
+func (r CheckResult) Outcome() Status {
+	if r.Status != "" {
+		return r.Status
+	}
+	if r.OK {
+		return StatusPass
+	}
+	return StatusFail
+}
+
+// zombieNodeStates are node statuses that should clear on their own once a node is decommissioned or
+// removed. A node that lingers in one of them is reported as a zombie.
+var zombieNodeStates = []string{"REMOVED", "REMOVING", "DECOMMISSIONING", "DECOMMISSIONED"}
+
+// nodeStateSinceFields are the keys the /node response may use for when a node entered its status.
+var nodeStateSinceFields = []string{"status_changed_at", "state_since", "updated_at", "last_updated"}
+
+// nodeStateSince returns when the node entered its current status, accepting RFC 3339 strings and epoch
+// seconds.
+func nodeStateSince(node map[string]interface{}) (time.Time, bool) {
+	for _, field := range nodeStateSinceFields {
+		switch value := node[field].(type) {
+		case string:
+			if since, err := time.Parse(time.RFC3339, value); err == nil {
+				return since, true
+			}
+		case float64:
+			return time.Unix(int64(value), 0), true
+		}
+	}
+	return time.Time{}, false
+}
 
 // getNodesStatus gives you the node status in the cluster
 // CheckNodesStatus makes a GET request to the /node endpoint and verifies that all nodes are ONLINE.
-func NodesStatus(token string, serviceIP string) string {
-	url := fmt.Sprintf("https://%s:9001/node", serviceIP)
+// Nodes stuck in a decommission or removal state are only warned about unless failOnZombie is set.
+func NodesStatus(ctx context.Context, auth Utils.Authenticator, serviceIP string, failOnZombie bool) CheckResult {
+	url := Utils.EndpointURL(serviceIP, "node")
 	// log.Printf("Triggering GET request to: %s", url)
 
-	client := Utils.GetInsecureHTTPClient()
-
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return fmt.Sprintf("failed to create request: %v", err)
+		return CheckResult{Name: "nodes", Detail: fmt.Sprintf("failed to create request: %v", err), Err: err}
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("x-rakuten-internal", "user")
-	req.Header.Set("x-rakuten-token", token)
 
-	resp, err := client.Do(req)
+	resp, err := Utils.DoAuthenticated(req, auth)
 	if err != nil {
-		return fmt.Sprintf("failed to execute request: %v", err)
+		return CheckResult{Name: "nodes", Detail: fmt.Sprintf("failed to execute request: %v", err), Err: err}
 	}
 	defer resp.Body.Close()
 
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Sprintf("failed to read response body: %v", err)
+		return CheckResult{Name: "nodes", Detail: fmt.Sprintf("failed to read response body: %v", err), Err: err}
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Sprintf("received non-successful HTTP status: %s. Body: %s", resp.Status, string(bodyBytes))
+		return CheckResult{Name: "nodes", Detail: fmt.Sprintf("received non-successful HTTP status: %s. Body: %s", resp.Status, string(bodyBytes))}
 	}
 
 	// --- THE FIX IS HERE ---
@@ -61,398 +138,704 @@ func NodesStatus(token string, serviceIP string) string {
 	// 1. Parse the JSON string into a generic interface{}.
 	parsedJSON, err := Utils.ParseJSON(bodyBytes)
 	if err != nil {
-		return fmt.Sprintf("failed to parse JSON response: %v", err)
+		return CheckResult{Name: "nodes", Detail: fmt.Sprintf("failed to parse JSON response: %v", err), Err: err}
 	}
 
 	// 2. Assert the type to a slice of interfaces ([]interface{}), which corresponds to a JSON array.
 	nodeList, ok := parsedJSON.([]interface{})
 	if !ok {
-		return fmt.Sprintf("unexpected JSON structure: expected an array of nodes, but got %T", parsedJSON)
+		return CheckResult{Name: "nodes", Detail: fmt.Sprintf("unexpected JSON structure: expected an array of nodes, but got %T", parsedJSON)}
 	}
 
-	log.Print(" Total number of Object Store Nodes: ", len(nodeList))
+	Logger.Print(ctx, " Total number of Object Store Nodes: ", len(nodeList))
 
 	// 3. Loop through each item in the slice.
+	zombies := []string{}
 	for i, item := range nodeList {
 		// Each item should be an object (map[string]interface{}).
 		nodeMap, ok := item.(map[string]interface{})
 		if !ok {
-			return fmt.Sprintf("unexpected item in JSON array at index %d: expected an object", i)
+			return CheckResult{Name: "nodes", Detail: fmt.Sprintf("unexpected item in JSON array at index %d: expected an object", i)}
 		}
 
 		// 4. Safely extract and check the 'health_str' field.
-		healthStr, healthOK := nodeMap["status_str"].(string)
-		nodeName, nameOK := nodeMap["name"].(string)
+		healthStr, healthOK := nodeMap[Utils.Field("node", "status_str")].(string)
+		nodeName, nameOK := nodeMap[Utils.Field("node", "name")].(string)
 
 		if !healthOK || !nameOK {
-			return "A node in the response is missing or has invalid 'health_str' or 'name' fields"
+			return CheckResult{Name: "nodes", Detail: "A node in the response is missing or has invalid 'health_str' or 'name' fields"}
 		}
 
-		log.Printf("✅ Checking Node: %s | Health: '%s'", nodeName, healthStr)
+		Logger.Debugf(ctx, Constants.SymbolOK+" Checking Node: %s | Health: '%s'", nodeName, healthStr)
 
 		// 5. Perform the validation.
+		if slices.Contains(zombieNodeStates, healthStr) {
+			zombie := fmt.Sprintf("node '%s' is lingering in state '%s'", nodeName, healthStr)
+			if since, found := nodeStateSince(nodeMap); found {
+				zombie += fmt.Sprintf(" for %s", time.Since(since).Round(time.Second))
+			}
+			if failOnZombie {
+				return CheckResult{Name: "nodes", Detail: Constants.SymbolFail + " " + zombie}
+			}
+			zombies = append(zombies, zombie)
+			continue
+		}
 		if healthStr != "ACTIVE" {
-			return fmt.Sprintf("node '%s' is not ACTIVE. Current health: '%s'", nodeName, healthStr)
+			return CheckResult{Name: "nodes", Detail: fmt.Sprintf("node '%s' is not ACTIVE. Current health: '%s'", nodeName, healthStr)}
+		}
+	}
+	if len(zombies) > 0 {
+		for _, zombie := range zombies {
+			Logger.Printf(ctx, Constants.SymbolWarn+" WARNING: %s", zombie)
 		}
+		Logger.Print(ctx, "All the remaining Nodes are Active, pass --fail-on-zombie-nodes to fail on lingering nodes"+Constants.TwoNewLines)
+		return CheckResult{Name: "nodes", OK: true, Status: StatusWarn, Detail: "lingering nodes: " + strings.Join(zombies, "; ")}
 	}
-	log.Print("All the Nodes are Active" + Constants.TwoNewLines)
+	Logger.Print(ctx, "All the Nodes are Active"+Constants.TwoNewLines)
 
-	return "Success"
+	return CheckResult{Name: "nodes", OK: true, Detail: fmt.Sprintf("all %d nodes are ACTIVE", len(nodeList))}
 }
 
-func ReplicationStatus(token string, serviceIP string) string {
-	url := fmt.Sprintf("https://%s:9000/cluster_replication_config", serviceIP)
+func ReplicationStatus(ctx context.Context, auth Utils.Authenticator, serviceIP string) CheckResult {
+	url := Utils.EndpointURL(serviceIP, "replication")
 	// log.Printf("Triggering GET request to: %s", url)
 
-	client := Utils.GetInsecureHTTPClient()
-
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return fmt.Sprintf("failed to create request: %v", err)
+		return CheckResult{Name: "replication", Detail: fmt.Sprintf("failed to create request: %v", err), Err: err}
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("x-rakuten-internal", "user")
-	req.Header.Set("x-rakuten-token", token)
 
-	resp, err := client.Do(req)
+	resp, err := Utils.DoAuthenticated(req, auth)
 	if err != nil {
-		return fmt.Sprintf("failed to execute request: %v", err)
+		return CheckResult{Name: "replication", Detail: fmt.Sprintf("failed to execute request: %v", err), Err: err}
 	}
 	defer resp.Body.Close()
 
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Sprintf("failed to read response body: %s", err)
+		return CheckResult{Name: "replication", Detail: fmt.Sprintf("failed to read response body: %s", err), Err: err}
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Sprintf("received non-successful HTTP status: %s. Body: %s", resp.Status, string(bodyBytes))
+		return CheckResult{Name: "replication", Detail: fmt.Sprintf("received non-successful HTTP status: %s. Body: %s", resp.Status, string(bodyBytes))}
 	}
 
 	if string(bodyBytes) == "{}" {
-		return "❌ Replication not set" + Constants.TwoNewLines
+		return CheckResult{Name: "replication", Detail: Constants.SymbolFail + " Replication not set" + Constants.TwoNewLines}
 	}
 
 	parsedJSON, err := Utils.ParseJSON(bodyBytes)
 	if err != nil {
-		return fmt.Sprintf("failed to parse JSON response: %s", err)
+		return CheckResult{Name: "replication", Detail: fmt.Sprintf("failed to parse JSON response: %s", err), Err: err}
 	}
 
 	parsedJSONMap, ok := parsedJSON.(map[string]interface{})
 	if !ok {
-		return "unexpected JSON structure: expected an object at the top level"
+		return CheckResult{Name: "replication", Detail: "unexpected JSON structure: expected an object at the top level"}
 	}
 
-	replicatedCluster, ok := parsedJSONMap["ReplicatedClusters"].([]interface{})
-	if !ok || len(replicatedCluster) == 0 {
-		return "unexpected JSON structure: expected an object in 'ReplicatedCluster' array"
+	replicatedClusters, ok := parsedJSONMap[Utils.Field("replication", "ReplicatedClusters")].([]interface{})
+	if !ok || len(replicatedClusters) == 0 {
+		return CheckResult{Name: "replication", Detail: "unexpected JSON structure: expected an object in 'ReplicatedCluster' array"}
 	}
 
-	firstCluster, ok := replicatedCluster[0].(map[string]interface{})
-	if !ok {
-		return "unexpected JSON structure: expected an object in 'ReplicatedCluster' array"
+	localRole := replicationRole(parsedJSONMap[Utils.Field("replication", "Role")])
+	Logger.Printf(ctx, " Replication role: local %s", displayRole(localRole))
+	if localRole == "" {
+		return CheckResult{Name: "replication", Detail: Constants.SymbolFail + " Replication is configured but the local cluster's replication role is undefined"}
 	}
 
-	health, ok := firstCluster["Health"].(string)
-	if !ok {
-		return "unexpected JSON structure: 'Health' field is missing or not a string"
+	// Every peer is checked so a degraded second target isn't hidden behind a healthy first one.
+	peers := []string{}
+	health := map[string]interface{}{}
+	problems := []string{}
+	for i, item := range replicatedClusters {
+		name := fmt.Sprintf("peer %d", i+1)
+		cluster, ok := item.(map[string]interface{})
+		if !ok {
+			problems = append(problems, name+": unexpected JSON structure, expected an object")
+			continue
+		}
+		if id, found := cluster[Utils.Field("replication", "Name")].(string); found && id != "" {
+			name = "peer '" + id + "'"
+		}
+
+		peerHealth, ok := cluster[Utils.Field("replication", "Health")].(string)
+		if !ok {
+			peerHealth = "UNKNOWN"
+			problems = append(problems, name+": 'Health' field is missing or not a string")
+		} else if peerHealth != "ONLINE" {
+			problems = append(problems, fmt.Sprintf("%s health is %s", name, peerHealth))
+		}
+		peerRole := replicationRole(cluster[Utils.Field("replication", "Role")])
+		if peerRole == localRole {
+			problems = append(problems, fmt.Sprintf("%s role conflicts: both the local cluster and the peer are %s", name, localRole))
+		}
+		Logger.Debugf(ctx, "Replication %s: health %s, role %s", name, peerHealth, displayRole(peerRole))
+		peers = append(peers, name+" "+peerHealth)
+		health[name] = peerHealth
 	}
 
-	if health != "ONLINE" {
-		return fmt.Sprintf("Replication is configured but the health is not Online, current health: %s", health)
+	data := map[string]interface{}{"peers": health}
+	if len(problems) > 0 {
+		return CheckResult{Name: "replication", Data: data, Detail: fmt.Sprintf(Constants.SymbolFail+" Replication is configured but not every peer is healthy: %s (peers: %s)", strings.Join(problems, "; "), strings.Join(peers, ", "))}
 	}
 
-	log.Print("✅ Replication is set" + Constants.TwoNewLines)
+	Logger.Print(ctx, Constants.SymbolOK+" Replication is set"+Constants.TwoNewLines)
 
-	return "Success"
+	return CheckResult{Name: "replication", OK: true, Data: data, Detail: fmt.Sprintf("replication is ONLINE, local %s, peers: %s", displayRole(localRole), strings.Join(peers, ", "))}
 }
 
-// OstoreVersion gives you the objectStore version installed in the cluster
-func OstoreVersion(token string, serviceIP string) string {
-	url := fmt.Sprintf("https://%s:9001/version", serviceIP)
-	// log.Printf("Triggering GET request to: %s", url)
+// replicationRoleAliases normalizes the role names gateways report for each end of a replication.
+var replicationRoleAliases = map[string]string{
+	"PRIMARY":   "PRIMARY",
+	"SOURCE":    "PRIMARY",
+	"MASTER":    "PRIMARY",
+	"REPLICA":   "REPLICA",
+	"SECONDARY": "REPLICA",
+	"TARGET":    "REPLICA",
+}
 
-	client := Utils.GetInsecureHTTPClient()
+// replicationRole returns the normalized replication role, or an empty string when the value is missing
+// or not a known role.
+func replicationRole(value interface{}) string {
+	role, _ := value.(string)
+	return replicationRoleAliases[strings.ToUpper(strings.TrimSpace(role))]
+}
+
+func displayRole(role string) string {
+	if role == "" {
+		return "undefined"
+	}
+	return role
+}
 
-	req, err := http.NewRequest("GET", url, nil)
+// OstoreVersion gives you the objectStore version installed in the cluster. When expected is set, a
+// version constraint such as "1.5.2" or ">=1.5.0", the check fails unless the version satisfies it.
+func OstoreVersion(ctx context.Context, auth Utils.Authenticator, serviceIP string, expected string) CheckResult {
+	url := Utils.EndpointURL(serviceIP, "version")
+	// log.Printf("Triggering GET request to: %s", url)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return fmt.Sprintf("failed to create request: %s", err)
+		return CheckResult{Name: "version", Detail: fmt.Sprintf("failed to create request: %s", err), Err: err}
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("x-rakuten-internal", "user")
-	req.Header.Set("x-rakuten-token", token)
 
-	resp, err := client.Do(req)
+	resp, err := Utils.DoAuthenticated(req, auth)
 	if err != nil {
-		return fmt.Sprintf("failed to execute request: %s", err)
+		return CheckResult{Name: "version", Detail: fmt.Sprintf("failed to execute request: %s", err), Err: err}
 	}
 	defer resp.Body.Close()
 
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Sprintf("failed to read response body: %s", err)
+		return CheckResult{Name: "version", Detail: fmt.Sprintf("failed to read response body: %s", err), Err: err}
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Sprintf("received non-successful HTTP status: %s. Body: %s", resp.Status, string(bodyBytes))
+		return CheckResult{Name: "version", Detail: fmt.Sprintf("received non-successful HTTP status: %s. Body: %s", resp.Status, string(bodyBytes))}
 	}
-	log.Print("Object Store version is: " + string(bodyBytes) + Constants.TwoNewLines)
+	Logger.Print(ctx, "Object Store version is: "+string(bodyBytes)+Constants.TwoNewLines)
 
-	return "Success"
+	actual := versionString(bodyBytes)
+	if expected == "" {
+		return CheckResult{Name: "version", OK: true, Detail: actual}
+	}
+	constraint, err := semver.NewConstraint(expected)
+	if err != nil {
+		return CheckResult{Name: "version", Detail: fmt.Sprintf("invalid expected version '%s': %s", expected, err), Err: err}
+	}
+	installed, err := semver.NewVersion(actual)
+	if err != nil {
+		return CheckResult{Name: "version", Detail: fmt.Sprintf(Constants.SymbolFail+" unable to parse version '%s' to compare it with %s: %s", actual, expected, err)}
+	}
+	if !constraint.Check(installed) {
+		return CheckResult{Name: "version", Detail: fmt.Sprintf(Constants.SymbolFail+" version %s does not match the expected %s", actual, expected)}
+	}
+	Logger.Printf(ctx, Constants.SymbolOK+" Version %s matches the expected %s", actual, expected)
+	Logger.Print(ctx, Constants.TwoNewLines)
+	return CheckResult{Name: "version", OK: true, Detail: fmt.Sprintf("version %s matches %s", actual, expected)}
 }
 
-// triggerPostRequest makes an insecure POST request and prints the full response.
-func DisksetStatus(token string, serviceIP string) string {
-	url := "https://" + serviceIP + ":9001/diskset?action=list"
-	// log.Printf("Triggering GET request to: %s", url)
+// versionString extracts the version from a /version response, which is either the bare version, a JSON
+// string or an object with a "version" field.
+func versionString(body []byte) string {
+	var document struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(body, &document); err == nil && document.Version != "" {
+		return document.Version
+	}
+	var quoted string
+	if err := json.Unmarshal(body, &quoted); err == nil {
+		return quoted
+	}
+	return strings.TrimSpace(string(body))
+}
+
+// RebuildLimits bounds how long a diskset may stay REBUILDING, across runs recorded in the state file,
+// before DisksetStatus reports it as stuck. A zero limit is not enforced.
+type RebuildLimits struct {
+	MaxRuns     int
+	MaxDuration time.Duration
+}
 
-	client := Utils.GetInsecureHTTPClient()
+// DisksetStatus lists the disksets and fails when one is not HEALTHY and ACTIVE or REBUILDING, or has
+// degraded members. How long each diskset has been REBUILDING is tracked across runs in state, and one
+// that stays REBUILDING beyond the limits is reported as stuck.
+func DisksetStatus(ctx context.Context, auth Utils.Authenticator, serviceIP string, state *Utils.State, limits RebuildLimits) CheckResult {
+	url := Utils.EndpointURL(serviceIP, "diskset")
+	// log.Printf("Triggering GET request to: %s", url)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return fmt.Sprintf("failed to create request: %v", err)
+		return CheckResult{Name: "diskset", Detail: fmt.Sprintf("failed to create request: %v", err), Err: err}
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("x-rakuten-internal", "user")
-	req.Header.Set("x-rakuten-token", token)
 
-	resp, err := client.Do(req)
+	resp, err := Utils.DoAuthenticated(req, auth)
 	if err != nil {
-		return fmt.Sprintf("failed to execute request: %s", err)
+		return CheckResult{Name: "diskset", Detail: fmt.Sprintf("failed to execute request: %s", err), Err: err}
 	}
 	defer resp.Body.Close()
 
 	// Read the body first to include it in potential error messages
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Sprintf("failed to read response body: %s", err)
+		return CheckResult{Name: "diskset", Detail: fmt.Sprintf("failed to read response body: %s", err), Err: err}
 	}
 
 	// Check for a successful status code
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Sprintf("received non-successful HTTP status: %s. Body: %s", resp.Status, string(bodyBytes))
+		return CheckResult{Name: "diskset", Detail: fmt.Sprintf("received non-successful HTTP status: %s. Body: %s", resp.Status, string(bodyBytes))}
 	}
 
 	// Return the body as a string on success
 
 	parsedJSON, err := Utils.ParseJSON(bodyBytes)
 	if err != nil {
-		return fmt.Sprintf("failed to parse JSON response: %s", err)
+		return CheckResult{Name: "diskset", Detail: fmt.Sprintf("failed to parse JSON response: %s", err), Err: err}
 	}
 
 	parsedJSONMap, ok := parsedJSON.(map[string]interface{})
 	if !ok {
-		return "unexpected JSON structure: expected an object at the top level"
+		return CheckResult{Name: "diskset", Detail: "unexpected JSON structure: expected an object at the top level"}
 	}
-	disksets := parsedJSONMap["disksets"].([]interface{})
-	log.Println("Total number of disksets on the cluster:", len(disksets))
-	for _, j := range disksets {
+	disksets, ok := parsedJSONMap[Utils.Field("diskset", "disksets")].([]interface{})
+	if !ok {
+		return CheckResult{Name: "diskset", Detail: fmt.Sprintf("unexpected response: '%s' field missing or not an array", Utils.Field("diskset", "disksets"))}
+	}
+	Logger.Println(ctx, "Total number of disksets on the cluster:", len(disksets))
 
-		disksetHealth := j.(map[string]interface{})["health_str"]
-		disksetID := j.(map[string]interface{})["id"]
-		disksetStatus := j.(map[string]interface{})["status_str"]
-		log.Printf("✅ Diskset ID: %v, Health : %v, Status: %v\n", disksetID, disksetHealth, disksetStatus)
+	// Carry rebuilds over from the previous run; disksets that finished rebuilding drop out. The records
+	// are only returned after every diskset was seen, so an early failure keeps the previous ones intact.
+	now := time.Now()
+	rebuilding := map[string]Utils.RebuildRecord{}
+	stuck := []string{}
+	for i, j := range disksets {
+		diskset, ok := j.(map[string]interface{})
+		if !ok {
+			return CheckResult{Name: "diskset", Detail: fmt.Sprintf("unexpected response: diskset at index %d is not an object", i)}
+		}
+		disksetHealth := diskset[Utils.Field("diskset", "health_str")]
+		disksetID := Utils.ID(diskset[Utils.Field("diskset", "id")])
+		disksetStatus := diskset[Utils.Field("diskset", "status_str")]
+		Logger.Debugf(ctx, Constants.SymbolOK+" Diskset ID: %v, Health : %v, Status: %v", disksetID, disksetHealth, disksetStatus)
 		if disksetHealth != "HEALTHY" || disksetStatus != "ACTIVE" && disksetStatus != "REBUILDING" {
-			return fmt.Sprintf("❌ Diskset ID %v is not healthy or active. Health: %v, Status: %v", disksetID, disksetHealth, disksetStatus)
+			return CheckResult{Name: "diskset", Detail: fmt.Sprintf(Constants.SymbolFail+" Diskset ID %v is not healthy or active. Health: %v, Status: %v", disksetID, disksetHealth, disksetStatus)}
+		}
+		// The aggregate health can stay HEALTHY while individual members degrade.
+		if degraded := degradedMembers(diskset); len(degraded) > 0 {
+			return CheckResult{Name: "diskset", Detail: fmt.Sprintf(Constants.SymbolFail+" Diskset ID %v reports %v but has degraded members: %s", disksetID, disksetHealth, strings.Join(degraded, ", "))}
+		}
+		if disksetStatus == "REBUILDING" {
+			record, found := state.Rebuilding[disksetID]
+			if !found {
+				record.Since = now
+			}
+			record.Runs++
+			rebuilding[disksetID] = record
+
+			elapsed := now.Sub(record.Since).Round(time.Second)
+			Logger.Printf(ctx, Constants.SymbolWarn+" Diskset ID %v has been REBUILDING for %s over %d run(s)", disksetID, elapsed, record.Runs)
+			if limits.MaxRuns > 0 && record.Runs > limits.MaxRuns || limits.MaxDuration > 0 && elapsed > limits.MaxDuration {
+				stuck = append(stuck, fmt.Sprintf("%v (rebuilding for %s over %d runs)", disksetID, elapsed, record.Runs))
+			}
 		}
 	}
+	result := CheckResult{Name: "diskset", State: &Utils.State{Rebuilding: rebuilding}}
 	if len(disksets) == 0 {
-		return "❌ There are no disksets present, User can not perform data operations\n"
+		result.Detail = Constants.SymbolFail + " There are no disksets present, User can not perform data operations\n"
+		return result
+	}
+	if len(stuck) > 0 {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" Diskset rebuild is not completing for diskset ID(s): %s", strings.Join(stuck, ", "))
+		return result
+	}
+	Logger.Print(ctx, "All the Diskset/Disksets are Healthy"+Constants.TwoNewLines)
+	result.OK = true
+	result.Detail = fmt.Sprintf("all %d disksets are healthy", len(disksets))
+	return result
+}
+
+// disksetMemberFields are the keys the /diskset response may use to enumerate a diskset's member disks.
+var disksetMemberFields = []string{"members", "disks", "member_disks"}
+
+// healthyMemberStates are the member disk health values that don't indicate degradation.
+var healthyMemberStates = []string{"HEALTHY", "ONLINE"}
+
+// degradedMembers returns the member disks of a diskset whose own health isn't healthy, described by ID
+// and health. Disksets that don't enumerate their members yield none.
+func degradedMembers(diskset map[string]interface{}) []string {
+	degraded := []string{}
+	for _, field := range disksetMemberFields {
+		members, ok := diskset[field].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, item := range members {
+			member, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			health, ok := member[Utils.Field("disk", "health_str")].(string)
+			if !ok || slices.Contains(healthyMemberStates, health) {
+				continue
+			}
+			degraded = append(degraded, fmt.Sprintf("disk %s (%s)", Utils.ID(member[Utils.Field("disk", "id")]), health))
+		}
+		break
 	}
-	log.Print("All the Diskset/Disksets are Healthy" + Constants.TwoNewLines)
-	return "Success"
+	return degraded
+}
+
+// DiskStatuses classifies the disk status strings DiskStatus accepts. Acceptable statuses pass, transient
+// ones (such as a disk still being provisioned) are reported as warnings, anything else fails.
+type DiskStatuses struct {
+	Acceptable []string
+	Transient  []string
 }
 
-func DiskStatus(token string, serviceIP string) string {
+// DiskStatus verifies every disk reported by the gateway is ONLINE and in a usable state. When a
+// clientset is available, each disk is correlated with the Kubernetes node it lives on so failures
+// name the node that needs physical attention.
+func DiskStatus(ctx context.Context, auth Utils.Authenticator, serviceIP string, clientset *kubernetes.Clientset, statuses DiskStatuses) CheckResult {
 	// ... (pasting the corrected function from above) ...
-	url := fmt.Sprintf("https://%s:9001/disk", serviceIP)
+	url := Utils.EndpointURL(serviceIP, "disk")
 	// log.Printf("Triggering GET request to: %s", url)
 
-	client := Utils.GetInsecureHTTPClient()
-
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return fmt.Sprintf("failed to create request: %s", err)
+		return CheckResult{Name: "disk", Detail: fmt.Sprintf("failed to create request: %s", err), Err: err}
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("x-rakuten-internal", "user")
-	req.Header.Set("x-rakuten-token", token)
 
-	resp, err := client.Do(req)
+	resp, err := Utils.DoAuthenticated(req, auth)
 	if err != nil {
-		return fmt.Sprintf("failed to execute request: %s", err)
+		return CheckResult{Name: "disk", Detail: fmt.Sprintf("failed to execute request: %s", err), Err: err}
 	}
 	defer resp.Body.Close()
 
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Sprintf("failed to read response body: %s", err)
+		return CheckResult{Name: "disk", Detail: fmt.Sprintf("failed to read response body: %s", err), Err: err}
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Sprintf("received non-successful HTTP status: %s. Body: %s", resp.Status, string(bodyBytes))
+		return CheckResult{Name: "disk", Detail: fmt.Sprintf("received non-successful HTTP status: %s. Body: %s", resp.Status, string(bodyBytes))}
 	}
 
 	parsedJSON, err := Utils.ParseJSON(bodyBytes)
 	if err != nil {
-		return fmt.Sprintf("failed to parse JSON response: %s", err)
+		return CheckResult{Name: "disk", Detail: fmt.Sprintf("failed to parse JSON response: %s", err), Err: err}
 	}
 
 	diskList, ok := parsedJSON.([]interface{})
 	if !ok {
-		return fmt.Sprintf("unexpected JSON structure: expected an array at the top level, but got %T", parsedJSON)
+		return CheckResult{Name: "disk", Detail: fmt.Sprintf("unexpected JSON structure: expected an array at the top level, but got %T", parsedJSON)}
 	}
 
-	log.Print("Total number of disks present in the ObjectStore Cluster: ", len(diskList))
+	Logger.Print(ctx, "Total number of disks present in the ObjectStore Cluster: ", len(diskList))
 	if len(diskList) == 0 {
-		return "❌ There are no disks present in the ObjectStore Cluster, A user can not perform data operations\n"
+		return CheckResult{Name: "disk", Detail: Constants.SymbolFail + " There are no disks present in the ObjectStore Cluster, A user can not perform data operations\n"}
 	}
 
+	nodeIndex := kubernetesNodeIndex(ctx, clientset)
+
 	for i, item := range diskList {
 		disk, ok := item.(map[string]interface{})
 		if !ok {
-			return fmt.Sprintf("unexpected item in JSON array at index %d: expected an object", i)
+			return CheckResult{Name: "disk", Detail: fmt.Sprintf("unexpected item in JSON array at index %d: expected an object", i)}
 		}
 
-		healthStr := disk["health_str"].(string)
-		statusStr := disk["status_str"].(string)
-		diskID := disk["disk_id"]
+		diskID := Utils.ID(disk[Utils.Field("disk", "disk_id")])
+		healthStr, ok := disk[Utils.Field("disk", "health_str")].(string)
+		if !ok {
+			return CheckResult{Name: "disk", Detail: fmt.Sprintf("unexpected response: '%s' field missing or not a string for disk %s", Utils.Field("disk", "health_str"), diskID)}
+		}
+		statusStr, ok := disk[Utils.Field("disk", "status_str")].(string)
+		if !ok {
+			return CheckResult{Name: "disk", Detail: fmt.Sprintf("unexpected response: '%s' field missing or not a string for disk %s", Utils.Field("disk", "status_str"), diskID)}
+		}
+		nodeName := diskNodeName(disk, nodeIndex)
 
 		if healthStr != "ONLINE" {
-			return fmt.Sprintf("❌  Disk with Id %0.f is unhealthy: expected ONLINE/OFFLINE, got health %s and status %s", diskID, healthStr, statusStr)
+			return CheckResult{Name: "disk", Detail: fmt.Sprintf(Constants.SymbolFail+"  Disk with Id %s on node %s is unhealthy: expected ONLINE/OFFLINE, got health %s and status %s", diskID, nodeName, healthStr, statusStr)}
 		}
 
-		if statusStr != "IN_USE" && statusStr != "UNUSED" {
-			return fmt.Sprintf("❌ Disk with Id %d has invalid status: expected IN_USE or UNUSED, got %s", diskID, statusStr)
+		if slices.Contains(statuses.Transient, statusStr) {
+			Logger.Printf(ctx, Constants.SymbolWarn+" Disk ID: %s, Node: %s is in transient status %s", diskID, nodeName, statusStr)
+			continue
+		}
+		if !slices.Contains(statuses.Acceptable, statusStr) {
+			return CheckResult{Name: "disk", Detail: fmt.Sprintf(Constants.SymbolFail+" Disk with Id %s on node %s has invalid status: expected one of %v (or transient %v), got %s", diskID, nodeName, statuses.Acceptable, statuses.Transient, statusStr)}
 		}
-		log.Printf("✅ Disk ID: %v, Health: %s, Status: %s", diskID, healthStr, statusStr)
+		Logger.Debugf(ctx, Constants.SymbolOK+" Disk ID: %s, Node: %s, Health: %s, Status: %s", diskID, nodeName, healthStr, statusStr)
 	}
-	log.Print("Success! All the Disks are Healthy" + Constants.TwoNewLines)
+	Logger.Print(ctx, "Success! All the Disks are Healthy"+Constants.TwoNewLines)
 
-	return "Success"
+	return CheckResult{Name: "disk", OK: true, Detail: fmt.Sprintf("all %d disks are healthy", len(diskList))}
 }
 
-func LDAPStatus(token string, serviceIP string) string {
-	url := fmt.Sprintf("https://%s:9001/idp?idp=ldap", serviceIP)
-	// log.Printf("Triggering GET request to: %s", url)
-
-	client := Utils.GetInsecureHTTPClient()
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return fmt.Sprintf("failed to create request: %v", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-rakuten-internal", "user")
-	req.Header.Set("x-rakuten-token", token)
+// diskNodeFields are the keys the /disk response may use to associate a disk with the node hosting it.
+var diskNodeFields = []string{"node_name", "hostname", "node", "node_ip"}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Sprintf("failed to execute request: %s", err)
-	}
-	defer resp.Body.Close()
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Sprintf("failed to read response body: %s", err)
-	}
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Sprintf("received non-successful HTTP status: %s. Body: %s", resp.Status, string(bodyBytes))
+// kubernetesNodeIndex maps node names, hostnames and addresses to the Kubernetes node name. It returns
+// an empty index when no clientset is available or the nodes can't be listed.
+func kubernetesNodeIndex(ctx context.Context, clientset *kubernetes.Clientset) map[string]string {
+	index := map[string]string{}
+	if clientset == nil {
+		return index
 	}
-	parsedJSON, err := Utils.ParseJSON(bodyBytes)
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
 	if err != nil {
-		return fmt.Sprintf("failed to parse JSON response: %s", err)
+		Logger.Printf(ctx, Constants.SymbolWarn+" Unable to list Kubernetes nodes for disk correlation: %v", err)
+		return index
 	}
-	parsedJSONMap, ok := parsedJSON.(map[string]interface{})
-	if !ok {
-		return "unexpected JSON structure: expected an object at the top level" + Constants.TwoNewLines
+	for _, node := range nodes.Items {
+		index[node.Name] = node.Name
+		for _, address := range node.Status.Addresses {
+			index[address.Address] = node.Name
+		}
 	}
-	status := parsedJSONMap["ldap_info"].(map[string]interface{})["status_str"]
-	server_address := parsedJSONMap["ldap_info"].(map[string]interface{})["ldap_server_address"]
-	if status == "DISABLED" && server_address == "" {
-		return "❌ LDAP is not configured" + Constants.TwoNewLines
+	return index
+}
+
+// diskNodeName resolves the Kubernetes node a disk lives on, falling back to the gateway's own node
+// reference when it can't be matched and to "unknown" when the disk carries no node association.
+func diskNodeName(disk map[string]interface{}, index map[string]string) string {
+	for _, field := range diskNodeFields {
+		value, ok := disk[field].(string)
+		if !ok || value == "" {
+			continue
+		}
+		if nodeName, found := index[value]; found {
+			return nodeName
+		}
+		return value
 	}
-	if status == "ENABLED" {
-		log.Print("✅ LDAP is configured and Enabled" + Constants.TwoNewLines)
+	return "unknown"
+}
+
+// idpTypes describes, for each identity provider type the gateway supports, the object its /idp
+// response nests the settings under and the field holding the address of the provider's server.
+var idpTypes = map[string]struct {
+	info    string
+	address string
+}{
+	"ldap": {"ldap_info", "ldap_server_address"},
+	"oidc": {"oidc_info", "issuer_url"},
+	"saml": {"saml_info", "idp_metadata_url"},
+}
+
+// IDPTypes returns the identity provider types IDPStatus knows, sorted.
+func IDPTypes() []string {
+	types := make([]string, 0, len(idpTypes))
+	for idp := range idpTypes {
+		types = append(types, idp)
 	}
-	if status == "DISABLED" && server_address != "" {
-		log.Print("⚠️ Ldap is Cconfigured but Disabled" + Constants.TwoNewLines)
+	sort.Strings(types)
+	return types
+}
+
+// IDPStatus reports, independently for each of the given identity provider types, whether it is enabled,
+// configured but disabled, not configured, or misconfigured. It fails when a provider is misconfigured
+// or none is enabled, and warns when one is configured but disabled. Providers the gateway doesn't
+// support are reported and otherwise ignored.
+func IDPStatus(ctx context.Context, auth Utils.Authenticator, serviceIP string, types []string) CheckResult {
+	result := CheckResult{Name: "idp"}
+	states := map[string]interface{}{}
+	summary := []string{}
+	problems := []string{}
+	disabled := []string{}
+	enabled := 0
+	for _, idp := range types {
+		fields, known := idpTypes[idp]
+		if !known {
+			problems = append(problems, fmt.Sprintf("unknown identity provider type '%s', known types: %s", idp, strings.Join(IDPTypes(), ", ")))
+			continue
+		}
+		label := strings.ToUpper(idp)
+		response, err := Utils.GetAndDecode[map[string]interface{}](ctx, Utils.GetInsecureHTTPClient(), Utils.EndpointURL(serviceIP, idp), auth)
+		var statusErr *Utils.StatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+			Logger.Printf(ctx, Constants.SymbolInfo+" %s is not supported by the gateway", label)
+			states[idp] = "not supported"
+			summary = append(summary, idp+" not supported")
+			continue
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("failed to get %s status: %s", label, err))
+			states[idp] = "error"
+			continue
+		}
+		info, ok := response[Utils.Field(idp, fields.info)].(map[string]interface{})
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unexpected %s response: '%s' field missing or not an object", label, Utils.Field(idp, fields.info)))
+			states[idp] = "error"
+			continue
+		}
+		status, _ := info[Utils.Field(idp, "status_str")].(string)
+		address, _ := info[Utils.Field(idp, fields.address)].(string)
+
+		switch {
+		case status == "ENABLED" && address != "":
+			Logger.Printf(ctx, Constants.SymbolOK+" %s is configured and enabled (%s)", label, address)
+			states[idp] = "enabled"
+			summary = append(summary, fmt.Sprintf("%s enabled (%s)", idp, address))
+			enabled++
+		case status == "ENABLED":
+			Logger.Printf(ctx, Constants.SymbolFail+" %s is enabled but has no server address", label)
+			problems = append(problems, fmt.Sprintf("%s is enabled but '%s' is empty", label, Utils.Field(idp, fields.address)))
+			states[idp] = "misconfigured"
+		case status == "DISABLED" && address != "":
+			Logger.Printf(ctx, Constants.SymbolWarn+" %s is configured but disabled", label)
+			states[idp] = "configured but disabled"
+			summary = append(summary, idp+" configured but disabled")
+			disabled = append(disabled, label)
+		case status == "DISABLED":
+			Logger.Printf(ctx, Constants.SymbolInfo+" %s is not configured", label)
+			states[idp] = "not configured"
+			summary = append(summary, idp+" not configured")
+		default:
+			Logger.Printf(ctx, Constants.SymbolFail+" %s reports unexpected status '%s'", label, status)
+			problems = append(problems, fmt.Sprintf("%s reports unexpected status '%s'", label, status))
+			states[idp] = "misconfigured"
+		}
 	}
-	return "Success"
+	Logger.Print(ctx, Constants.TwoNewLines)
+	result.Data = map[string]interface{}{"providers": states}
+
+	switch {
+	case len(problems) > 0:
+		result.Detail = Constants.SymbolFail + " " + strings.Join(problems, "; ")
+		if len(summary) > 0 {
+			result.Detail += " (" + strings.Join(summary, ", ") + ")"
+		}
+	case enabled == 0:
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" no identity provider is enabled: %s", strings.Join(summary, ", "))
+	case len(disabled) > 0:
+		result.OK = true
+		result.Status = StatusWarn
+		result.Detail = fmt.Sprintf("%s configured but disabled: %s", strings.Join(disabled, ", "), strings.Join(summary, ", "))
+	default:
+		result.OK = true
+		result.Detail = strings.Join(summary, ", ")
+	}
+	return result
 }
 
-func ClusterHealth(token string, serviceIP string) string {
-	url := fmt.Sprintf("https://%s:9001/cluster_health", serviceIP)
+func ClusterHealth(ctx context.Context, auth Utils.Authenticator, serviceIP string) CheckResult {
+	url := Utils.EndpointURL(serviceIP, "cluster_health")
 	// log.Printf("Triggering GET request to: %s", url)
-	client := Utils.GetInsecureHTTPClient()
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return fmt.Sprintf("failed to create request: %s", err)
+		return CheckResult{Name: "cluster-health", Detail: fmt.Sprintf("failed to create request: %s", err), Err: err}
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("x-rakuten-internal", "user")
-	req.Header.Set("x-rakuten-token", token)
 
-	resp, err := client.Do(req)
+	resp, err := Utils.DoAuthenticated(req, auth)
 	if err != nil {
-		return fmt.Sprintf("failed to execute request: %s", err)
+		return CheckResult{Name: "cluster-health", Detail: fmt.Sprintf("failed to execute request: %s", err), Err: err}
 	}
 	defer resp.Body.Close()
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Sprintf("failed to read response body: %s", err)
+		return CheckResult{Name: "cluster-health", Detail: fmt.Sprintf("failed to read response body: %s", err), Err: err}
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Sprintf("received non-successful HTTP status: %s. Body: %s", resp.Status, string(bodyBytes))
+		return CheckResult{Name: "cluster-health", Detail: fmt.Sprintf("received non-successful HTTP status: %s. Body: %s", resp.Status, string(bodyBytes))}
 	}
 	parsedJSON, err := Utils.ParseJSON(bodyBytes)
 	if err != nil {
-		return fmt.Sprintf("failed to parse JSON response: %s", err)
+		return CheckResult{Name: "cluster-health", Detail: fmt.Sprintf("failed to parse JSON response: %s", err), Err: err}
 	}
 	parsedJSONMap, ok := parsedJSON.(map[string]interface{})
 	if !ok {
-		return "unexpected JSON structure: expected an object at the top level"
+		return CheckResult{Name: "cluster-health", Detail: "unexpected JSON structure: expected an object at the top level"}
 	}
-	controlHealthStatus := parsedJSONMap["controlHealthStatus"]
+	controlHealthStatus := parsedJSONMap[Utils.Field("cluster_health", "controlHealthStatus")]
 	if controlHealthStatus != "Online" {
-		return fmt.Sprintf("❌ Cluster health check failed: expected Online, got %s", controlHealthStatus)
+		return CheckResult{Name: "cluster-health", Detail: fmt.Sprintf(Constants.SymbolFail+" Cluster health check failed: expected Online, got %s", controlHealthStatus)}
 	} else {
-		log.Println("✅ Control Path is Online")
+		Logger.Println(ctx, Constants.SymbolOK+" Control Path is Online")
 	}
-	metadataHealthStatus := parsedJSONMap["metadataHealthStatus"]
+	metadataHealthStatus := parsedJSONMap[Utils.Field("cluster_health", "metadataHealthStatus")]
 	if metadataHealthStatus != "Online" {
-		return fmt.Sprintf("❌ Cluster health check failed: expected Online, got %s", metadataHealthStatus)
+		return CheckResult{Name: "cluster-health", Detail: fmt.Sprintf(Constants.SymbolFail+" Cluster health check failed: expected Online, got %s", metadataHealthStatus)}
 	} else {
-		log.Println("✅ Metadata store status is Online")
+		Logger.Println(ctx, Constants.SymbolOK+" Metadata store status is Online")
 	}
-	datapathHealthStatus := parsedJSONMap["datapathHealthStatus"]
+	datapathHealthStatus := parsedJSONMap[Utils.Field("cluster_health", "datapathHealthStatus")]
 	if datapathHealthStatus != "Online" {
-		return fmt.Sprintf("❌ Cluster health check failed: expected Online, got %s", datapathHealthStatus)
+		return CheckResult{Name: "cluster-health", Detail: fmt.Sprintf(Constants.SymbolFail+" Cluster health check failed: expected Online, got %s", datapathHealthStatus)}
 	} else {
-		log.Println("✅ Data Path is Online")
+		Logger.Println(ctx, Constants.SymbolOK+" Data Path is Online")
 	}
-	clusterStatus := parsedJSONMap["clusterHealthStatus"]
+	clusterStatus := parsedJSONMap[Utils.Field("cluster_health", "clusterHealthStatus")]
 	if clusterStatus != "Online" {
-		return fmt.Sprintf("❌ Cluster health check failed: expected Online, got %s", clusterStatus)
+		return CheckResult{Name: "cluster-health", Detail: fmt.Sprintf(Constants.SymbolFail+" Cluster health check failed: expected Online, got %s", clusterStatus)}
 	} else {
-		log.Print("✅ Cluster Health is Online" + Constants.TwoNewLines)
+		Logger.Print(ctx, Constants.SymbolOK+" Cluster Health is Online"+Constants.TwoNewLines)
 	}
 
-	return "Success"
+	return CheckResult{Name: "cluster-health", OK: true, Detail: "control path, metadata store, data path and cluster are Online"}
 }
 
-// CheckClusterHealth performs a series of checks against critical cluster components.
-func KubernetesHealth(clientset *kubernetes.Clientset) error {
-	log.Println(" Checking core component status...")
-	componentStatuses, err := clientset.CoreV1().ComponentStatuses().List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("❌ failed to list component statuses: %w", err)
+// KubernetesHealth performs a series of checks against critical cluster components: the component
+// statuses (or control-plane pods), node readiness and the pods in controlPlaneNamespace.
+func KubernetesHealth(ctx context.Context, clientset *kubernetes.Clientset, controlPlaneNamespace string) CheckResult {
+	if err := kubernetesHealth(ctx, clientset, controlPlaneNamespace); err != nil {
+		return CheckResult{Name: "kubernetes", Detail: fmt.Sprintf(Constants.SymbolFail+" Core Kubernetes health check FAILED: %s", err), Err: err}
+	}
+	Logger.Print(ctx, Constants.SymbolOK+" Core Kubernetes components are healthy."+Constants.TwoNewLines)
+	return CheckResult{Name: "kubernetes", OK: true, Detail: "core components, nodes and control-plane pods are healthy"}
+}
+
+// kubernetesHealth implements KubernetesHealth, returning the first problem found.
+func kubernetesHealth(ctx context.Context, clientset *kubernetes.Clientset, controlPlaneNamespace string) error {
+	Logger.Println(ctx, " Checking core component status...")
+	componentStatuses, err := clientset.CoreV1().ComponentStatuses().List(ctx, metav1.ListOptions{})
+	if err != nil || len(componentStatuses.Items) == 0 {
+		// ComponentStatuses is deprecated and returns nothing useful on newer clusters.
+		Logger.Printf(ctx, Constants.SymbolWarn+" ComponentStatuses unavailable (%v), falling back to control-plane pod health in '%s'", componentStatusesReason(err), controlPlaneNamespace)
+		if err := controlPlanePodsHealthy(ctx, clientset, controlPlaneNamespace); err != nil {
+			return err
+		}
+		componentStatuses = &v1.ComponentStatusList{}
 	}
 	for _, cs := range componentStatuses.Items {
 		isHealthy := false
@@ -465,13 +848,13 @@ func KubernetesHealth(clientset *kubernetes.Clientset) error {
 		if !isHealthy {
 			return fmt.Errorf("component '%s' is not healthy. Conditions: %+v", cs.Name, cs.Conditions)
 		}
-		log.Printf("✅ Component '%s' is healthy.", cs.Name)
+		Logger.Debugf(ctx, Constants.SymbolOK+" Component '%s' is healthy.", cs.Name)
 	}
-	fmt.Print(Constants.TwoNewLines)
-	log.Println(" Checking all Kubernetes cluster nodes are ready...")
-	nodes, err := clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	Logger.Print(ctx, Constants.TwoNewLines)
+	Logger.Println(ctx, " Checking all Kubernetes cluster nodes are ready...")
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
 	if err != nil {
-		return fmt.Errorf("❌ failed to list nodes: %w", err)
+		return fmt.Errorf(Constants.SymbolFail+" failed to list nodes: %w", err)
 	}
 	for _, node := range nodes.Items {
 		isNodeReady := false
@@ -482,30 +865,134 @@ func KubernetesHealth(clientset *kubernetes.Clientset) error {
 			}
 		}
 		if !isNodeReady {
-			return fmt.Errorf("❌ node '%s' is not ready. Status: %+v", node.Name, node.Status.Conditions)
+			return fmt.Errorf(Constants.SymbolFail+" node '%s' is not ready. Status: %+v", node.Name, node.Status.Conditions)
 		}
-		log.Printf("✅ Kubernetes Node '%s' is ready.", node.Name)
+		Logger.Debugf(ctx, Constants.SymbolOK+" Kubernetes Node '%s' is ready.", node.Name)
 	}
-	fmt.Print(Constants.TwoNewLines)
-	log.Printf("Checking all pods in '%s' namespace...", kubeSystemNamespace)
+	Logger.Print(ctx, Constants.TwoNewLines)
+	Logger.Printf(ctx, "Checking all pods in '%s' namespace...", controlPlaneNamespace)
 	// For kube-system, we don't have a list of required pods, so we pass 'nil'.
-	if isSuccess := AllPodsAreRunning(clientset, kubeSystemNamespace, nil); isSuccess != "Success" {
-		return fmt.Errorf("health check for pods in '%s' failed: %s", kubeSystemNamespace, isSuccess)
+	if result := AllPodsAreRunning(ctx, clientset, controlPlaneNamespace, nil); !result.OK {
+		return fmt.Errorf("health check for pods in '%s' failed: %s", controlPlaneNamespace, result.Detail)
+	}
+
+	return nil
+}
+
+// controlPlaneComponents are the values of the "component" label kubeadm-style static pods carry for the
+// control plane.
+var controlPlaneComponents = []string{"etcd", "kube-apiserver", "kube-scheduler", "kube-controller-manager"}
+
+func componentStatusesReason(err error) string {
+	if err != nil {
+		return err.Error()
 	}
+	return "empty response"
+}
 
+// controlPlanePodsHealthy verifies the control-plane pods in namespace are running and ready. Managed
+// clusters don't expose their control plane as pods, in which case the check is skipped.
+func controlPlanePodsHealthy(ctx context.Context, clientset *kubernetes.Clientset, namespace string) error {
+	selector := "component in (" + strings.Join(controlPlaneComponents, ",") + ")"
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return fmt.Errorf(Constants.SymbolFail+" failed to list control-plane pods: %w", err)
+	}
+	if len(pods.Items) == 0 {
+		Logger.Print(ctx, Constants.SymbolWarn+" No control-plane pods found, the control plane is likely managed by the provider; skipping")
+		return nil
+	}
+	for _, pod := range pods.Items {
+		ready := false
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == v1.PodReady && condition.Status == v1.ConditionTrue {
+				ready = true
+				break
+			}
+		}
+		if pod.Status.Phase != v1.PodRunning || !ready {
+			return fmt.Errorf("control-plane pod '%s' is not healthy. Phase: %s", pod.Name, pod.Status.Phase)
+		}
+		Logger.Debugf(ctx, Constants.SymbolOK+" Control-plane pod '%s' is healthy.", pod.Name)
+	}
 	return nil
 }
 
-// checkAllPodsAreRunning verifies that all pods are ready and that a specific list of required pods exists.
-// It returns "Success" if all checks pass, otherwise it returns a descriptive error message.
-func AllPodsAreRunning(clientset *kubernetes.Clientset, namespace string, requiredPodPrefixes []string) string {
-	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+// containerCreateFailures explains the waiting reasons of containers that could not be created, which
+// point at configuration or secret problems rather than at pulling the image.
+var containerCreateFailures = map[string]string{
+	"CreateContainerConfigError": "the container config could not be generated, usually a missing Secret or ConfigMap key referenced by the pod",
+	"CreateContainerError":       "the runtime failed to create the container, check volume mounts, security context and command",
+	"InvalidImageName":           "the image reference is malformed",
+	"ImageInspectError":          "the runtime could not inspect the image",
+}
+
+// AllPodsAreRunning verifies that all pods in namespace are ready and that a pod exists for each of the
+// required prefixes.
+func AllPodsAreRunning(ctx context.Context, clientset kubernetes.Interface, namespace string, requiredPodPrefixes []string) CheckResult {
+	problem, _, _ := allPodsAreRunning(ctx, clientset, namespace, "", requiredPodPrefixes)
+	if problem != "" {
+		return CheckResult{Name: "pods", Detail: problem}
+	}
+	return CheckResult{Name: "pods", OK: true, Detail: "all pods are running and ready"}
+}
+
+// RequiredPods runs AllPodsAreRunning and records which running pods satisfied each required prefix, so
+// operators can confirm the right pods matched. The mapping is logged at DEBUG and carried in the result's
+// data under "matches", and how long each matched pod has been Ready under "ready_for". When minReady is
+// positive, a matched pod that became Ready more recently is still stabilizing and yields a warning. Pods
+// behind gatewayService that are draining connections during a rollout are reported but not failed.
+func RequiredPods(ctx context.Context, clientset *kubernetes.Clientset, namespace, gatewayService string, requiredPodPrefixes []string, minReady time.Duration) CheckResult {
+	problem, matched, readySince := allPodsAreRunning(ctx, clientset, namespace, gatewayService, requiredPodPrefixes)
+	for _, prefix := range requiredPodPrefixes {
+		Logger.Debugf(ctx, "Required pod prefix '%s' matched: %s", prefix, strings.Join(matched[prefix], ", "))
+	}
+
+	readyFor := map[string]string{}
+	stabilizing := []string{}
+	for _, prefix := range requiredPodPrefixes {
+		for _, name := range matched[prefix] {
+			since := readySince[name]
+			if since.IsZero() {
+				continue
+			}
+			age := time.Since(since).Round(time.Second)
+			readyFor[name] = age.String()
+			Logger.Debugf(ctx, "Pod '%s' has been ready for %s", name, age)
+			if minReady > 0 && age < minReady {
+				stabilizing = append(stabilizing, fmt.Sprintf("pod '%s' ready for %s", name, age))
+			}
+		}
+	}
+
+	result := CheckResult{
+		Name:   "pods",
+		OK:     problem == "",
+		Detail: problem,
+		Data:   map[string]interface{}{"matches": matched, "ready_for": readyFor},
+	}
+	if result.OK && len(stabilizing) > 0 {
+		Logger.Printf(ctx, Constants.SymbolWarn+" %d required pod(s) became ready less than %s ago and may still be stabilizing"+Constants.TwoNewLines, len(stabilizing), minReady)
+		result.Status = StatusWarn
+		result.Detail = fmt.Sprintf("pods stabilizing (ready for less than %s): %s", minReady, strings.Join(stabilizing, "; "))
+	}
+	return result
+}
+
+// allPodsAreRunning implements AllPodsAreRunning, returning the first problem found or "" when there is
+// none, along with the names of the running pods that matched each required prefix and when each of them
+// last became Ready. A terminating pod only passes when it is behind gatewayService and still within its
+// grace period; with no gatewayService every terminating pod fails.
+func allPodsAreRunning(ctx context.Context, clientset kubernetes.Interface, namespace, gatewayService string, requiredPodPrefixes []string) (string, map[string][]string, map[string]time.Time) {
+	matched := map[string][]string{}
+	readySince := map[string]time.Time{}
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
-		return fmt.Sprintf("❌ failed to list pods in namespace %s: %s", namespace, err)
+		return fmt.Sprintf(Constants.SymbolFail+" failed to list pods in namespace %s: %s", namespace, err), matched, readySince
 	}
 
 	if len(pods.Items) == 0 && len(requiredPodPrefixes) > 0 {
-		return fmt.Sprintf("❌ no pods found in namespace '%s', but required pods were expected", namespace)
+		return fmt.Sprintf(Constants.SymbolFail+" no pods found in namespace '%s', but required pods were expected", namespace), matched, readySince
 	}
 
 	// Create a map to track if we've found each required pod.
@@ -516,27 +1003,54 @@ func AllPodsAreRunning(clientset *kubernetes.Clientset, namespace string, requir
 	}
 	// }
 
+	// gatewayPods holds the pods behind the gateway service, listed when the first terminating pod is seen.
+	var gatewayPods map[string]bool
+
 	// First, iterate through all pods to check their status and mark required pods as found.
 	for _, pod := range pods.Items {
 		// --- NEW Check 1: Pod must not be Terminating ---
+		// A gateway pod still inside its grace period is draining as part of a rollout, which is expected.
 		if pod.ObjectMeta.DeletionTimestamp != nil {
-			return fmt.Sprintf("❌ pod '%s' is terminating", pod.Name)
+			if gatewayPods == nil {
+				gatewayPods = serviceEndpointPods(ctx, clientset, namespace, gatewayService)
+			}
+			switch {
+			case !gatewayPods[pod.Name]:
+				return fmt.Sprintf(Constants.SymbolFail+" pod '%s' is terminating", pod.Name), matched, readySince
+			case !isDraining(pod):
+				return fmt.Sprintf(Constants.SymbolFail+" pod '%s' is stuck terminating past its grace period", pod.Name), matched, readySince
+			}
+			Logger.Printf(ctx, Constants.SymbolInfo+" Gateway pod '%s' is draining connections (terminating within its grace period), skipping", pod.Name)
+			continue
 		}
 
 		// --- NEW Check 2: Pod must not be Evicted ---
 		if pod.Status.Reason == "Evicted" {
-			return fmt.Sprintf("❌ pod '%s' has been evicted. Check node status and resource limits", pod.Name)
+			return fmt.Sprintf(Constants.SymbolFail+" pod '%s' has been evicted. Check node status and resource limits", pod.Name), matched, readySince
 		}
 
 		// Ignore pods that have completed their lifecycle (like Jobs)
 		if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
-			log.Printf("  -> Skipping pod '%s' with status '%s'.", pod.Name, pod.Status.Phase)
+			Logger.Debugf(ctx, "Skipping pod '%s' with status '%s'.", pod.Name, pod.Status.Phase)
 			continue
 		}
 
+		// Containers that can't even be created leave the pod Pending; name the cause rather than the phase.
+		for _, containerStatus := range append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+			if waiting := containerStatus.State.Waiting; waiting != nil && containerCreateFailures[waiting.Reason] != "" {
+				return fmt.Sprintf(Constants.SymbolFail+" container '%s' in pod '%s' cannot start. Reason: %s - %s (%s)",
+					containerStatus.Name, pod.Name, waiting.Reason, containerCreateFailures[waiting.Reason], waiting.Message), matched, readySince
+			}
+		}
+
+		// An Unknown phase means the pod's node stopped reporting, so point at the node rather than the pod.
+		if pod.Status.Phase == v1.PodUnknown {
+			return fmt.Sprintf(Constants.SymbolFail+" pod '%s' is in 'Unknown' phase: the kubelet on node '%s' is likely unreachable. Check the node's connectivity and status", pod.Name, pod.Spec.NodeName), matched, readySince
+		}
+
 		// --- Check 3: Pod must be in Running phase ---
 		if pod.Status.Phase != v1.PodRunning {
-			return fmt.Sprintf("❌ pod '%s' is not in 'Running' phase. Current phase: '%s'", pod.Name, pod.Status.Phase)
+			return fmt.Sprintf(Constants.SymbolFail+" pod '%s' is not in 'Running' phase. Current phase: '%s'", pod.Name, pod.Status.Phase), matched, readySince
 		}
 
 		// --- Check 4: All containers must be ready and not in a failure loop ---
@@ -548,45 +1062,48 @@ func AllPodsAreRunning(clientset *kubernetes.Clientset, namespace string, requir
 					message := containerStatus.State.Waiting.Message
 					// NEW: Specific checks for common errors
 					if reason == "CrashLoopBackOff" || reason == "ImagePullBackOff" || reason == "ErrImagePull" {
-						return fmt.Sprintf("❌ container '%s' in pod '%s' is not ready. Reason: %s - %s",
-							containerStatus.Name, pod.Name, reason, message)
+						return fmt.Sprintf(Constants.SymbolFail+" container '%s' in pod '%s' is not ready. Reason: %s - %s",
+							containerStatus.Name, pod.Name, reason, message), matched, readySince
 					}
 					// Generic waiting message
-					return fmt.Sprintf("❌ container '%s' in pod '%s' is in a waiting state. Reason: %s - %s",
-						containerStatus.Name, pod.Name, reason, message)
+					return fmt.Sprintf(Constants.SymbolFail+" container '%s' in pod '%s' is in a waiting state. Reason: %s - %s",
+						containerStatus.Name, pod.Name, reason, message), matched, readySince
 				}
 
 				// NEW: Check if the container has terminated with an error
 				if containerStatus.State.Terminated != nil {
-					return fmt.Sprintf("❌ container '%s' in pod '%s' has terminated with exit code %d. Reason: %s",
-						containerStatus.Name, pod.Name, containerStatus.State.Terminated.ExitCode, containerStatus.State.Terminated.Reason)
+					return fmt.Sprintf(Constants.SymbolFail+" container '%s' in pod '%s' has terminated with exit code %d. Reason: %s",
+						containerStatus.Name, pod.Name, containerStatus.State.Terminated.ExitCode, containerStatus.State.Terminated.Reason), matched, readySince
 				}
 
 				// Fallback for any other non-ready state
-				return fmt.Sprintf("❌ container '%s' in pod '%s' is not ready for an unknown reason", containerStatus.Name, pod.Name)
+				return fmt.Sprintf(Constants.SymbolFail+" container '%s' in pod '%s' is not ready for an unknown reason", containerStatus.Name, pod.Name), matched, readySince
 			}
 		}
 
 		// --- Check 5: Pod must be marked as Ready in its conditions ---
 		isPodReady := false
+		var readyAt time.Time
 		for _, condition := range pod.Status.Conditions {
 			if condition.Type == v1.PodReady && condition.Status == v1.ConditionTrue {
 				isPodReady = true
+				readyAt = condition.LastTransitionTime.Time
 				break
 			}
 		}
 		if !isPodReady {
-			return fmt.Sprintf("❌ pod '%s' is not ready. Check its readiness probes and conditions", pod.Name)
+			return fmt.Sprintf(Constants.SymbolFail+" pod '%s' is not ready. Check its readiness probes and conditions", pod.Name), matched, readySince
 		}
 
-		log.Printf("✅ Pod '%s' is running and ready.", pod.Name)
+		Logger.Debugf(ctx, Constants.SymbolOK+" Pod '%s' is running and ready.", pod.Name)
 
 		// --- Check 6: Mark required pods as found ---
 
 		for _, prefix := range requiredPodPrefixes {
-			// Use the map to avoid re-checking already found prefixes
-			if !foundPods[prefix] && strings.HasPrefix(pod.Name, prefix) {
+			if strings.HasPrefix(pod.Name, prefix) {
 				foundPods[prefix] = true
+				matched[prefix] = append(matched[prefix], pod.Name)
+				readySince[pod.Name] = readyAt
 			}
 		}
 
@@ -596,41 +1113,2728 @@ func AllPodsAreRunning(clientset *kubernetes.Clientset, namespace string, requir
 	if requiredPodPrefixes != nil {
 		for prefix, found := range foundPods {
 			if !found {
-				return fmt.Sprint("❌ Following pod not found: " + prefix + Constants.TwoNewLines)
+				return fmt.Sprint(Constants.SymbolFail + " Following pod not found: " + prefix + Constants.TwoNewLines), matched, readySince
+			}
+		}
+	}
+	return "", matched, readySince
+}
+
+// serviceEndpointPods returns the names of the pods behind serviceName's EndpointSlices, which still list
+// terminating pods while they drain. It returns an empty set when serviceName is empty or the slices can't
+// be listed.
+func serviceEndpointPods(ctx context.Context, clientset kubernetes.Interface, namespace, serviceName string) map[string]bool {
+	pods := map[string]bool{}
+	if serviceName == "" {
+		return pods
+	}
+	endpointSlices, err := clientset.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: discoveryv1.LabelServiceName + "=" + serviceName,
+	})
+	if err != nil {
+		Logger.Printf(ctx, Constants.SymbolWarn+" Unable to list endpoints of service '%s': %v", serviceName, err)
+		return pods
+	}
+	for _, slice := range endpointSlices.Items {
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.TargetRef != nil && endpoint.TargetRef.Kind == "Pod" {
+				pods[endpoint.TargetRef.Name] = true
 			}
 		}
 	}
-	return "Success"
+	return pods
+}
+
+// isDraining reports whether a terminating pod is still within its deletion grace period. The API server
+// sets the deletion timestamp to the moment the grace period ends.
+func isDraining(pod v1.Pod) bool {
+	return pod.DeletionTimestamp != nil && time.Now().Before(pod.DeletionTimestamp.Time)
+}
+
+// LocalPVsAreBound verifies that all PersistentVolumes with the 'local-pv-' prefix are in a 'Bound' state.
+func LocalPVsAreBound(ctx context.Context, clientset *kubernetes.Clientset) CheckResult {
+	if err := localPVsAreBound(ctx, clientset); err != nil {
+		return CheckResult{Name: "pv", Detail: err.Error(), Err: err}
+	}
+	return CheckResult{Name: "pv", OK: true, Detail: "all local PVs are bound"}
 }
 
-// CheckLocalPVsAreBound verifies that all PersistentVolumes with the 'local-pv-' prefix are in a 'Bound' state.
-func LocalPVsAreBound(clientset *kubernetes.Clientset) error {
-	pvList, err := clientset.CoreV1().PersistentVolumes().List(context.TODO(), metav1.ListOptions{})
+// localPVsAreBound implements LocalPVsAreBound, returning the first problem found.
+func localPVsAreBound(ctx context.Context, clientset *kubernetes.Clientset) error {
+	pvList, err := clientset.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to list PersistentVolumes: %w", err)
 	}
 
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list nodes: %w", err)
+	}
+	// readyNodes maps both node names and hostname labels to the node's readiness.
+	readyNodes := make(map[string]bool)
+	for _, node := range nodes.Items {
+		ready := false
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == v1.NodeReady && condition.Status == v1.ConditionTrue {
+				ready = true
+				break
+			}
+		}
+		readyNodes[node.Name] = ready
+		if hostname, ok := node.Labels[v1.LabelHostname]; ok {
+			readyNodes[hostname] = ready
+		}
+	}
+
 	foundMatchingPV := false // Keep track if we find any PVs with the prefix
 
 	// 2. Iterate through all PVs and check the ones with the 'local-pv-' prefix
 	for _, pv := range pvList.Items {
 		if strings.HasPrefix(pv.Name, "local-pv-") {
 			foundMatchingPV = true
-			log.Printf("✅ Checking PV: %-25s | Status: %s", pv.Name, pv.Status.Phase)
+			Logger.Debugf(ctx, Constants.SymbolOK+" Checking PV: %-25s | Status: %s", pv.Name, pv.Status.Phase)
 
 			// 3. Check if the status is 'Bound'
 			if pv.Status.Phase != v1.VolumeBound {
 				// 4. If not bound, return an error immediately
-				return fmt.Errorf("❌ persistent volume '%s' is not in 'Bound' state. Current state: '%s'", pv.Name, pv.Status.Phase)
+				return fmt.Errorf(Constants.SymbolFail+" persistent volume '%s' is not in 'Bound' state. Current state: '%s'", pv.Name, pv.Status.Phase)
+			}
+
+			// 5. The node the PV is pinned to must still exist and be Ready, otherwise its data is stranded
+			if err := pvNodeAffinityValid(pv, readyNodes); err != nil {
+				return err
 			}
 		}
 	}
 
 	// Handle the case where no PVs with the prefix were found
 	if !foundMatchingPV {
-		log.Println("⚠️ No Local PersistentVolumes were found.")
+		Logger.Println(ctx, Constants.SymbolWarn+" No Local PersistentVolumes were found.")
 	}
-	log.Print(" Success! All Local PersistentVolumes are in the 'Bound' state." + Constants.TwoNewLines)
+	Logger.Print(ctx, " Success! All Local PersistentVolumes are in the 'Bound' state."+Constants.TwoNewLines)
 
 	return nil
 }
+
+// pvNodeAffinityValid verifies that the hostnames a local PV's nodeAffinity pins it to include at least
+// one existing Ready node.
+func pvNodeAffinityValid(pv v1.PersistentVolume, readyNodes map[string]bool) error {
+	hostnames := pvHostnames(pv)
+	if len(hostnames) == 0 {
+		return nil
+	}
+
+	for _, hostname := range hostnames {
+		if readyNodes[hostname] {
+			return nil
+		}
+	}
+	for _, hostname := range hostnames {
+		if _, exists := readyNodes[hostname]; exists {
+			return fmt.Errorf(Constants.SymbolFail+" persistent volume '%s' is pinned to node '%s' which is not Ready", pv.Name, hostname)
+		}
+	}
+	return fmt.Errorf(Constants.SymbolFail+" persistent volume '%s' is pinned to node(s) %v which no longer exist in the cluster", pv.Name, hostnames)
+}
+
+// pvHostnames returns the hostnames a local PV's nodeAffinity pins it to.
+func pvHostnames(pv v1.PersistentVolume) []string {
+	hostnames := []string{}
+	if pv.Spec.NodeAffinity == nil || pv.Spec.NodeAffinity.Required == nil {
+		return hostnames
+	}
+	for _, term := range pv.Spec.NodeAffinity.Required.NodeSelectorTerms {
+		for _, expression := range term.MatchExpressions {
+			if expression.Key == v1.LabelHostname && expression.Operator == v1.NodeSelectorOpIn {
+				hostnames = append(hostnames, expression.Values...)
+			}
+		}
+	}
+	return hostnames
+}
+
+// readOnlyFilesystemCondition is the node condition node-problem-detector sets when the kernel remounted
+// a filesystem read-only.
+const readOnlyFilesystemCondition = v1.NodeConditionType("ReadonlyFilesystem")
+
+// diskReadOnlyFields are the keys the /disk response may use to flag a read-only filesystem, and
+// diskPathFields those giving the disk's mount path.
+var (
+	diskReadOnlyFields = []string{"read_only", "readonly", "is_read_only"}
+	diskPathFields     = []string{"mount_path", "mount_point", "path"}
+)
+
+// diskIsReadOnly reports whether the gateway flags a disk's filesystem as read-only, and whether the
+// disk exposes that at all.
+func diskIsReadOnly(disk map[string]interface{}) (readOnly bool, exposed bool) {
+	for _, field := range diskReadOnlyFields {
+		if value, ok := disk[field].(bool); ok {
+			return value, true
+		}
+	}
+	for _, field := range []string{Utils.Field("disk", "status_str"), Utils.Field("disk", "health_str")} {
+		if value, ok := disk[field].(string); ok && (value == "READ_ONLY" || value == "READONLY") {
+			return true, true
+		}
+	}
+	return false, false
+}
+
+// LocalPVsReadOnly flags local PVs whose backing filesystem was remounted read-only, typically after an
+// I/O error, which leaves the PV Bound but unusable. It relies on the ReadonlyFilesystem node condition
+// set by node-problem-detector and on read-only disks reported by the gateway, matched to PVs by node and,
+// when the disk exposes it, by mount path. The disks are only fetched when auth is set. The check is
+// skipped when neither source is available.
+func LocalPVsReadOnly(ctx context.Context, clientset *kubernetes.Clientset, auth Utils.Authenticator, serviceIP string) CheckResult {
+	result := CheckResult{Name: "pv-read-only"}
+	pvList, err := clientset.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to list PersistentVolumes: %s", err)
+		return result
+	}
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to list nodes: %s", err)
+		return result
+	}
+
+	// readOnly maps a node name or hostname to the read-only paths found on it; an empty path stands for
+	// the whole node.
+	readOnly := map[string][]string{}
+	hostnames := map[string]string{}
+	exposed := false
+	for _, node := range nodes.Items {
+		hostnames[node.Name] = node.Name
+		if hostname, ok := node.Labels[v1.LabelHostname]; ok {
+			hostnames[node.Name] = hostname
+		}
+		for _, condition := range node.Status.Conditions {
+			if condition.Type != readOnlyFilesystemCondition {
+				continue
+			}
+			exposed = true
+			if condition.Status == v1.ConditionTrue {
+				Logger.Printf(ctx, Constants.SymbolWarn+" Node %s reports a read-only filesystem: %s", node.Name, condition.Message)
+				readOnly[hostnames[node.Name]] = append(readOnly[hostnames[node.Name]], "")
+			}
+		}
+	}
+
+	if auth == nil {
+		Logger.Print(ctx, Constants.SymbolInfo+" No gateway token, relying on node conditions only")
+	} else if disksJSON, err := fetchJSON(ctx, auth, Utils.EndpointURL(serviceIP, "disk")); err != nil {
+		Logger.Printf(ctx, Constants.SymbolWarn+" Unable to get disks, relying on node conditions only: %v", err)
+	} else {
+		diskList, _ := disksJSON.([]interface{})
+		nodeIndex := kubernetesNodeIndex(ctx, clientset)
+		for _, item := range diskList {
+			disk, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			diskReadOnly, diskExposed := diskIsReadOnly(disk)
+			exposed = exposed || diskExposed
+			if !diskReadOnly {
+				continue
+			}
+			nodeName := diskNodeName(disk, nodeIndex)
+			path := ""
+			for _, field := range diskPathFields {
+				if value, ok := disk[field].(string); ok && value != "" {
+					path = value
+					break
+				}
+			}
+			Logger.Printf(ctx, Constants.SymbolWarn+" Disk ID: %s on node %s is read-only", Utils.ID(disk[Utils.Field("disk", "disk_id")]), nodeName)
+			hostname, found := hostnames[nodeName]
+			if !found {
+				hostname = nodeName
+			}
+			readOnly[hostname] = append(readOnly[hostname], path)
+		}
+	}
+
+	if !exposed {
+		Logger.Print(ctx, Constants.SymbolInfo+" Neither node conditions nor the disk response expose read-only filesystems, skipping check"+Constants.TwoNewLines)
+		result.OK = true
+		result.Status = StatusSkip
+		result.Detail = "read-only state not exposed"
+		return result
+	}
+
+	affected := []string{}
+	for _, pv := range pvList.Items {
+		if !strings.HasPrefix(pv.Name, "local-pv-") {
+			continue
+		}
+		for _, hostname := range pvHostnames(pv) {
+			paths, found := readOnly[hostname]
+			if !found {
+				continue
+			}
+			if slices.Contains(paths, "") || (pv.Spec.Local != nil && slices.Contains(paths, pv.Spec.Local.Path)) {
+				affected = append(affected, fmt.Sprintf("PV '%s' on node '%s'", pv.Name, hostname))
+				break
+			}
+		}
+	}
+
+	if len(affected) > 0 {
+		result.Detail = Constants.SymbolFail + " local PVs backed by a read-only filesystem: " + strings.Join(affected, ", ")
+		return result
+	}
+
+	Logger.Print(ctx, Constants.SymbolOK+" No local PV is backed by a read-only filesystem"+Constants.TwoNewLines)
+	result.OK = true
+	return result
+}
+
+// CheckImageRegistries verifies that every container image on the pods in the namespace is pulled from
+// one of the allowed registries. An empty allowlist disables the check.
+func CheckImageRegistries(ctx context.Context, clientset *kubernetes.Clientset, namespace string, allowed []string) CheckResult {
+	result := CheckResult{Name: "image-registries"}
+	if len(allowed) == 0 {
+		Logger.Print(ctx, Constants.SymbolWarn+" No registry allowlist configured, skipping image registry check"+Constants.TwoNewLines)
+		result.OK = true
+		result.Status = StatusSkip
+		result.Detail = "no registry allowlist configured"
+		return result
+	}
+
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to list pods in namespace %s: %s", namespace, err)
+		return result
+	}
+
+	offending := []string{}
+	for _, pod := range pods.Items {
+		containers := append(append([]v1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+		for _, container := range containers {
+			if !registryAllowed(container.Image, allowed) {
+				Logger.Printf(ctx, Constants.SymbolFail+" Pod '%s' container '%s' uses image '%s' from registry '%s'", pod.Name, container.Name, container.Image, imageRegistry(container.Image))
+				offending = append(offending, fmt.Sprintf("pod '%s' image '%s'", pod.Name, container.Image))
+			}
+		}
+	}
+
+	if len(offending) > 0 {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" Images pulled from registries outside the allowlist %v: %s", allowed, strings.Join(offending, ", "))
+		return result
+	}
+
+	Logger.Print(ctx, Constants.SymbolOK+" All container images are pulled from allowed registries"+Constants.TwoNewLines)
+	result.OK = true
+	result.Detail = "all container images are pulled from allowed registries"
+	return result
+}
+
+// imageRegistry returns the registry host an image reference is pulled from. Unqualified references
+// such as "nginx:1.25" resolve to Docker Hub.
+func imageRegistry(image string) string {
+	first, _, found := strings.Cut(image, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		return first
+	}
+	return "docker.io"
+}
+
+// imageTag returns the tag of an image reference, "" when it has none, and whether the reference is
+// pinned by digest.
+func imageTag(image string) (string, bool) {
+	if strings.Contains(image, "@") {
+		return "", true
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	if _, tag, found := strings.Cut(name, ":"); found {
+		return tag, false
+	}
+	return "", false
+}
+
+// ImageTags flags ostore containers running the "latest" tag or no tag at all, which makes the deployed
+// version unpredictable. Images pinned by digest are accepted. Offenders fail the check unless
+// failOnLatest is false, in which case they are reported as a warning.
+func ImageTags(ctx context.Context, clientset *kubernetes.Clientset, namespace string, failOnLatest bool) CheckResult {
+	result := CheckResult{Name: "image-tags"}
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to list pods in namespace %s: %s", namespace, err)
+		return result
+	}
+
+	offending := []string{}
+	for _, pod := range pods.Items {
+		containers := append(append([]v1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+		for _, container := range containers {
+			tag, pinned := imageTag(container.Image)
+			if pinned || tag != "" && tag != "latest" {
+				continue
+			}
+			Logger.Printf(ctx, Constants.SymbolWarn+" Pod '%s' container '%s' uses unpinned image '%s'", pod.Name, container.Name, container.Image)
+			offending = append(offending, fmt.Sprintf("pod '%s' container '%s' image '%s'", pod.Name, container.Name, container.Image))
+		}
+	}
+
+	if len(offending) > 0 {
+		if failOnLatest {
+			result.Detail = fmt.Sprintf(Constants.SymbolFail+" Images use the latest tag or no tag: %s", strings.Join(offending, ", "))
+			return result
+		}
+		Logger.Print(ctx, Constants.TwoNewLines)
+		result.OK = true
+		result.Status = StatusWarn
+		result.Detail = fmt.Sprintf("images use the latest tag or no tag: %s", strings.Join(offending, ", "))
+		return result
+	}
+
+	Logger.Print(ctx, Constants.SymbolOK+" All container images use an explicit tag or digest"+Constants.TwoNewLines)
+	result.OK = true
+	result.Detail = "all container images use an explicit tag or digest"
+	return result
+}
+
+// registryAllowed reports whether the image comes from an allowed registry. Entries may name a bare
+// registry ("quay.io") or a registry path prefix ("quay.io/ostore").
+func registryAllowed(image string, allowed []string) bool {
+	registry := imageRegistry(image)
+	for _, entry := range allowed {
+		entry = strings.TrimSuffix(entry, "/")
+		if entry == registry || strings.HasPrefix(image, entry+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadBalancerIngress reports every ingress entry published on the gateway service and warns when the
+// LoadBalancer lists more than one, which usually means stale addresses survived a migration. When probe
+// is set each entry is dialed on the admin port so the report states which address is reachable.
+func LoadBalancerIngress(ctx context.Context, clientset *kubernetes.Clientset, namespace, serviceName, serviceIP string, probe bool) CheckResult {
+	result := CheckResult{Name: "loadbalancer-ingress"}
+	service, err := clientset.CoreV1().Services(namespace).Get(ctx, serviceName, metav1.GetOptions{})
+	if err != nil {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to get service '%s' in namespace '%s': %s", serviceName, namespace, err)
+		return result
+	}
+
+	endpoints := Utils.LoadBalancerEndpoints(service)
+	Logger.Printf(ctx, " LoadBalancer ingress entries for '%s': %v", serviceName, endpoints)
+	if len(endpoints) <= 1 {
+		Logger.Printf(ctx, Constants.SymbolOK+" Using '%s': it is the only LoadBalancer ingress entry", serviceIP)
+		Logger.Print(ctx, Constants.TwoNewLines)
+		result.OK = true
+		result.Detail = fmt.Sprintf("single ingress entry, using %s", serviceIP)
+		return result
+	}
+
+	Logger.Printf(ctx, Constants.SymbolWarn+" Service '%s' lists %d LoadBalancer ingress entries, some may be stale", serviceName, len(endpoints))
+	if !probe {
+		Logger.Printf(ctx, Constants.SymbolWarn+" Using '%s': it is the first ingress entry (probing disabled)", serviceIP)
+		Logger.Print(ctx, Constants.TwoNewLines)
+		result.OK = true
+		result.Status = StatusWarn
+		result.Detail = fmt.Sprintf("%d ingress entries %v, using first entry %s (probing disabled)", len(endpoints), endpoints, serviceIP)
+		return result
+	}
+
+	port := Utils.AdminPort()
+	reachable := []string{}
+	for _, endpoint := range endpoints {
+		if Utils.IsReachable(endpoint, port, 3*time.Second) {
+			Logger.Debugf(ctx, Constants.SymbolOK+" Ingress entry '%s' is reachable on port %s", endpoint, port)
+			reachable = append(reachable, endpoint)
+		} else {
+			Logger.Printf(ctx, Constants.SymbolFail+" Ingress entry '%s' is not reachable on port %s", endpoint, port)
+		}
+	}
+
+	if len(reachable) == 0 {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" none of the LoadBalancer ingress entries %v for service '%s' are reachable on port %s", endpoints, serviceName, port)
+		return result
+	}
+
+	for _, endpoint := range reachable {
+		if endpoint == serviceIP {
+			Logger.Printf(ctx, Constants.SymbolOK+" Using '%s': it is reachable on port %s", serviceIP, port)
+			Logger.Print(ctx, Constants.TwoNewLines)
+			result.OK = true
+			result.Detail = fmt.Sprintf("%d ingress entries %v, using reachable entry %s", len(endpoints), endpoints, serviceIP)
+			return result
+		}
+	}
+
+	result.Detail = fmt.Sprintf(Constants.SymbolFail+" service IP '%s' is not reachable on port %s, reachable ingress entries are %v", serviceIP, port, reachable)
+	return result
+}
+
+// HelmHooks inspects the hooks recorded on the Helm release and fails when a hook Job failed, either
+// according to the release's last run of the hook or the Job still present in the cluster. The pod check
+// skips Failed pods, so a botched upgrade hook would otherwise go unnoticed.
+func HelmHooks(ctx context.Context, clientset *kubernetes.Clientset, rel *release.Release) CheckResult {
+	result := CheckResult{Name: "helm-hooks"}
+	failed := []string{}
+
+	for _, hook := range rel.Hooks {
+		if hook.Kind != "Job" {
+			continue
+		}
+
+		if hook.LastRun.Phase == release.HookPhaseFailed {
+			Logger.Printf(ctx, Constants.SymbolFail+" Hook '%s' (events %v) last run FAILED", hook.Name, hook.Events)
+			failed = append(failed, fmt.Sprintf("hook '%s' job '%s'", hook.Path, hook.Name))
+			continue
+		}
+
+		job, err := clientset.BatchV1().Jobs(rel.Namespace).Get(ctx, hook.Name, metav1.GetOptions{})
+		if err != nil {
+			// Hook Jobs are commonly removed by their delete policy once they succeed.
+			Logger.Debugf(ctx, Constants.SymbolOK+" Hook '%s' last run: '%s' (Job no longer present)", hook.Name, hook.LastRun.Phase)
+			continue
+		}
+
+		if jobFailed(job) {
+			Logger.Printf(ctx, Constants.SymbolFail+" Hook '%s' Job '%s' is in Failed state", hook.Path, job.Name)
+			failed = append(failed, fmt.Sprintf("hook '%s' job '%s'", hook.Path, job.Name))
+			continue
+		}
+		Logger.Debugf(ctx, Constants.SymbolOK+" Hook '%s' Job '%s' has not failed", hook.Path, job.Name)
+	}
+
+	if len(failed) > 0 {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" Helm release '%s' has failed hook jobs: %s", rel.Name, strings.Join(failed, ", "))
+		return result
+	}
+
+	Logger.Print(ctx, Constants.SymbolOK+" No failed Helm hook jobs found for release "+rel.Name+Constants.TwoNewLines)
+	result.OK = true
+	result.Detail = "no failed Helm hook jobs"
+	return result
+}
+
+// jobFailed reports whether the Job carries a true Failed condition.
+func jobFailed(job *batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == v1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchJSON performs an authenticated GET against a gateway endpoint and decodes the JSON body.
+func fetchJSON(ctx context.Context, auth Utils.Authenticator, url string) (interface{}, error) {
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-rakuten-internal", "user")
+
+	resp, err := Utils.DoAuthenticated(req, auth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("received non-successful HTTP status: %s. Body: %s", resp.Status, string(bodyBytes))
+	}
+
+	return Utils.ParseJSON(bodyBytes)
+}
+
+// ObjectCount compares the bucket and object counts reported by the gateway against the previous run
+// recorded in the state file, and flags a drop larger than maxDropPct percent as CRITICAL since it may
+// indicate data loss or accidental deletion. The current counts are returned in the result's State for
+// the next run.
+func ObjectCount(ctx context.Context, auth Utils.Authenticator, serviceIP string, state *Utils.State, maxDropPct float64) CheckResult {
+	result := CheckResult{Name: "object-count"}
+	bucketList, err := Utils.GetAndDecode[[]map[string]interface{}](ctx, Utils.GetInsecureHTTPClient(), Utils.EndpointURL(serviceIP, "bucket"), auth)
+	if err != nil {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to list buckets: %s", err)
+		return result
+	}
+
+	current := &Utils.ObjectCounts{Buckets: int64(len(bucketList))}
+	for _, bucket := range bucketList {
+		if objects, ok := Utils.Int64(bucket[Utils.Field("bucket", "object_count")]); ok {
+			current.Objects += objects
+		}
+	}
+	Logger.Printf(ctx, " Current counts: %d buckets, %d objects", current.Buckets, current.Objects)
+
+	previous := state.Objects
+	result.State = &Utils.State{Objects: current}
+	if previous == nil {
+		Logger.Print(ctx, Constants.SymbolOK+" No previous object counts recorded, saving the current counts as the baseline"+Constants.TwoNewLines)
+		result.OK = true
+		result.Detail = fmt.Sprintf("baseline recorded: %d buckets, %d objects", current.Buckets, current.Objects)
+		return result
+	}
+	Logger.Printf(ctx, " Previous counts: %d buckets, %d objects", previous.Buckets, previous.Objects)
+
+	for _, count := range []struct {
+		name              string
+		previous, current int64
+	}{
+		{"objects", previous.Objects, current.Objects},
+		{"buckets", previous.Buckets, current.Buckets},
+	} {
+		if count.previous <= 0 || count.current >= count.previous {
+			continue
+		}
+		dropPct := float64(count.previous-count.current) / float64(count.previous) * 100
+		if dropPct > maxDropPct {
+			result.Detail = fmt.Sprintf(Constants.SymbolFail+" CRITICAL: %s count dropped by %.1f%% since the previous run (previous: %d, current: %d, allowed drop: %.1f%%)",
+				count.name, dropPct, count.previous, count.current, maxDropPct)
+			return result
+		}
+		Logger.Printf(ctx, Constants.SymbolWarn+" %s count dropped by %.1f%% (previous: %d, current: %d), within the allowed %.1f%%", count.name, dropPct, count.previous, count.current, maxDropPct)
+		result.Status = StatusWarn
+	}
+
+	Logger.Print(ctx, Constants.SymbolOK+" Object counts have not dropped unexpectedly since the previous run"+Constants.TwoNewLines)
+	result.OK = true
+	result.Detail = fmt.Sprintf("previous: %d buckets, %d objects; current: %d buckets, %d objects", previous.Buckets, previous.Objects, current.Buckets, current.Objects)
+	return result
+}
+
+// DisksetCount compares the number of disksets reported by the gateway against the previous run recorded
+// in the state file and flags any decrease as CRITICAL, since it can mean lost storage. A planned removal
+// is only warned about when allowDecrease is set. The current count is returned in the result's State
+// for the next run.
+func DisksetCount(ctx context.Context, auth Utils.Authenticator, serviceIP string, state *Utils.State, allowDecrease bool) CheckResult {
+	result := CheckResult{Name: "diskset-count"}
+	parsedJSON, err := fetchJSON(ctx, auth, Utils.EndpointURL(serviceIP, "diskset"))
+	if err != nil {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to get disksets: %s", err)
+		return result
+	}
+	parsedJSONMap, ok := parsedJSON.(map[string]interface{})
+	if !ok {
+		result.Detail = "unexpected JSON structure: expected an object at the top level"
+		return result
+	}
+	disksets, _ := parsedJSONMap[Utils.Field("diskset", "disksets")].([]interface{})
+	current := int64(len(disksets))
+	Logger.Printf(ctx, " Current diskset count: %d", current)
+
+	previous := state.Disksets
+	result.State = &Utils.State{Disksets: &current}
+	if previous == nil {
+		Logger.Print(ctx, Constants.SymbolOK+" No previous diskset count recorded, saving the current count as the baseline"+Constants.TwoNewLines)
+		result.OK = true
+		result.Detail = fmt.Sprintf("baseline recorded: %d disksets", current)
+		return result
+	}
+	Logger.Printf(ctx, " Previous diskset count: %d", *previous)
+
+	result.Detail = fmt.Sprintf("previous: %d disksets; current: %d disksets", *previous, current)
+	if current < *previous {
+		if !allowDecrease {
+			result.Detail = fmt.Sprintf(Constants.SymbolFail+" CRITICAL: diskset count dropped since the previous run (previous: %d, current: %d); pass --allow-diskset-decrease for a planned removal", *previous, current)
+			return result
+		}
+		Logger.Printf(ctx, Constants.SymbolWarn+" Diskset count dropped (previous: %d, current: %d), allowed by --allow-diskset-decrease%s", *previous, current, Constants.TwoNewLines)
+		result.OK = true
+		result.Status = StatusWarn
+		return result
+	}
+
+	Logger.Print(ctx, Constants.SymbolOK+" The diskset count has not decreased since the previous run"+Constants.TwoNewLines)
+	result.OK = true
+	return result
+}
+
+// KubernetesVersion checks that the API server version falls within the range supported by the
+// detected chart, as listed in Constants.SupportedKubernetesVersions for the selector matching its
+// version.
+func KubernetesVersion(ctx context.Context, clientset *kubernetes.Clientset, chartName, chartVersion string) CheckResult {
+	result := CheckResult{Name: "kubernetes-version"}
+	info, err := clientset.Discovery().ServerVersion()
+	if err != nil {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to get Kubernetes server version: %s", err)
+		return result
+	}
+	Logger.Printf(ctx, " Kubernetes API server version: %s", info.GitVersion)
+
+	serverVersion, err := version.ParseGeneric(info.GitVersion)
+	if err != nil {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" unable to parse Kubernetes server version '%s': %s", info.GitVersion, err)
+		return result
+	}
+
+	chart := chartName + "-" + chartVersion
+	supported, found := supportedKubernetesVersions(chartName, chartVersion)
+	if !found {
+		Logger.Printf(ctx, Constants.SymbolWarn+" No supported Kubernetes version range known for chart '%s', skipping compatibility check", chart)
+		Logger.Print(ctx, Constants.TwoNewLines)
+		result.OK = true
+		result.Status = StatusSkip
+		result.Detail = fmt.Sprintf("server version %s, no supported range known for chart %s", info.GitVersion, chart)
+		return result
+	}
+
+	minVersion := version.MustParseGeneric(supported[0])
+	maxVersion := version.MustParseGeneric(supported[1])
+	tooNew := serverVersion.Major() > maxVersion.Major() ||
+		serverVersion.Major() == maxVersion.Major() && serverVersion.Minor() > maxVersion.Minor()
+	if !serverVersion.AtLeast(minVersion) || tooNew {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" Kubernetes version %s is outside the range supported by chart %s (%s - %s)", info.GitVersion, chart, supported[0], supported[1])
+		return result
+	}
+
+	Logger.Printf(ctx, Constants.SymbolOK+" Kubernetes version %s is supported by chart %s (%s - %s)", info.GitVersion, chart, supported[0], supported[1])
+	Logger.Print(ctx, Constants.TwoNewLines)
+	result.OK = true
+	result.Detail = fmt.Sprintf("server version %s is within %s - %s", info.GitVersion, supported[0], supported[1])
+	return result
+}
+
+// supportedKubernetesVersions returns the Kubernetes version range of the first selector in
+// Constants.SupportedKubernetesVersions, in alphabetical order, that matches the chart.
+func supportedKubernetesVersions(name, version string) ([2]string, bool) {
+	specs := make([]string, 0, len(Constants.SupportedKubernetesVersions))
+	for spec := range Constants.SupportedKubernetesVersions {
+		specs = append(specs, spec)
+	}
+	sort.Strings(specs)
+	for _, spec := range specs {
+		selector, err := Utils.ParseChartSelector(spec)
+		if err == nil && selector.Matches(name, version) {
+			return Constants.SupportedKubernetesVersions[spec], true
+		}
+	}
+	return [2]string{}, false
+}
+
+// DiskMembership cross-checks the /disk and /diskset responses and verifies that every IN_USE disk
+// belongs to exactly one diskset. A healthy disk outside any diskset is wasted or misconfigured
+// capacity. The check is skipped when neither response exposes membership.
+func DiskMembership(ctx context.Context, auth Utils.Authenticator, serviceIP string) CheckResult {
+	result := CheckResult{Name: "disk-membership"}
+	disksJSON, err := fetchJSON(ctx, auth, Utils.EndpointURL(serviceIP, "disk"))
+	if err != nil {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to get disks: %s", err)
+		return result
+	}
+	disksetsJSON, err := fetchJSON(ctx, auth, Utils.EndpointURL(serviceIP, "diskset"))
+	if err != nil {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to get disksets: %s", err)
+		return result
+	}
+
+	diskList, ok := disksJSON.([]interface{})
+	if !ok {
+		result.Detail = fmt.Sprintf("unexpected JSON structure: expected an array of disks, but got %T", disksJSON)
+		return result
+	}
+	disksetMap, ok := disksetsJSON.(map[string]interface{})
+	if !ok {
+		result.Detail = "unexpected JSON structure: expected an object at the top level of the diskset response"
+		return result
+	}
+	disksets, _ := disksetMap[Utils.Field("diskset", "disksets")].([]interface{})
+
+	// memberships counts, per disk ID, how many disksets claim the disk.
+	memberships := map[string]int{}
+	membershipExposed := false
+	for _, item := range disksets {
+		diskset, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		members, ok := diskset[Utils.Field("diskset", "disks")].([]interface{})
+		if !ok {
+			continue
+		}
+		membershipExposed = true
+		for _, member := range members {
+			if memberMap, ok := member.(map[string]interface{}); ok {
+				member = memberMap[Utils.Field("diskset", "disk_id")]
+			}
+			memberships[Utils.ID(member)]++
+		}
+	}
+
+	orphaned, duplicated := []string{}, []string{}
+	for _, item := range diskList {
+		disk, ok := item.(map[string]interface{})
+		if !ok || disk[Utils.Field("disk", "status_str")] != "IN_USE" {
+			continue
+		}
+		diskID := Utils.ID(disk[Utils.Field("disk", "disk_id")])
+		count := memberships[diskID]
+		if disksetID, found := disk[Utils.Field("disk", "diskset_id")]; found && !membershipExposed {
+			membershipExposed = true
+			if disksetID != nil && Utils.ID(disksetID) != "0" && Utils.ID(disksetID) != "" {
+				count = 1
+			}
+		}
+		switch {
+		case count == 0:
+			orphaned = append(orphaned, diskID)
+		case count > 1:
+			duplicated = append(duplicated, diskID)
+		}
+	}
+
+	if !membershipExposed {
+		Logger.Print(ctx, Constants.SymbolWarn+" Disk and diskset responses do not expose membership, skipping membership check"+Constants.TwoNewLines)
+		result.OK = true
+		result.Status = StatusSkip
+		result.Detail = "membership not exposed by the gateway"
+		return result
+	}
+
+	if len(orphaned) > 0 || len(duplicated) > 0 {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" Inconsistent diskset membership: orphaned IN_USE disks %v, disks in more than one diskset %v", orphaned, duplicated)
+		return result
+	}
+
+	Logger.Print(ctx, Constants.SymbolOK+" Every IN_USE disk belongs to exactly one diskset"+Constants.TwoNewLines)
+	result.OK = true
+	result.Detail = "every IN_USE disk belongs to exactly one diskset"
+	return result
+}
+
+// GatewayDrainStatus reports gateway endpoints that are draining connections during a rolling update.
+// Draining endpoints are informational; the check only fails when no ready endpoint remains behind the
+// service, since requests would then fail outright.
+func GatewayDrainStatus(ctx context.Context, clientset *kubernetes.Clientset, namespace, serviceName string) CheckResult {
+	result := CheckResult{Name: "gateway-drain"}
+	endpointSlices, err := clientset.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: discoveryv1.LabelServiceName + "=" + serviceName,
+	})
+	if err != nil {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to list endpoints of service '%s': %s", serviceName, err)
+		return result
+	}
+
+	ready, draining := 0, []string{}
+	for _, slice := range endpointSlices.Items {
+		for _, endpoint := range slice.Endpoints {
+			name := strings.Join(endpoint.Addresses, ",")
+			if endpoint.TargetRef != nil {
+				name = endpoint.TargetRef.Name
+			}
+			switch {
+			case endpoint.Conditions.Terminating != nil && *endpoint.Conditions.Terminating:
+				Logger.Printf(ctx, Constants.SymbolInfo+" Gateway endpoint '%s' is draining connections", name)
+				draining = append(draining, name)
+			case endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready:
+				ready++
+			}
+		}
+	}
+
+	if ready == 0 {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" service '%s' has no ready gateway endpoints (draining: %v)", serviceName, draining)
+		return result
+	}
+
+	result.OK = true
+	if len(draining) > 0 {
+		Logger.Printf(ctx, Constants.SymbolInfo+" %d gateway endpoint(s) draining, %d ready; a rolling update is likely in progress", len(draining), ready)
+		Logger.Print(ctx, Constants.TwoNewLines)
+		result.Detail = fmt.Sprintf("%d ready endpoints, draining: %v", ready, draining)
+		return result
+	}
+	Logger.Printf(ctx, Constants.SymbolOK+" %d gateway endpoint(s) ready, none draining", ready)
+	Logger.Print(ctx, Constants.TwoNewLines)
+	result.Detail = fmt.Sprintf("%d ready endpoints, none draining", ready)
+	return result
+}
+
+// ecSchemeFields are the keys the /diskset response may use for a diskset's redundancy scheme.
+var ecSchemeFields = []string{"ec_scheme", "redundancy", "protection_scheme"}
+
+var ecSchemePattern = regexp.MustCompile(`(\d+)\D+(\d+)`)
+
+// failuresTolerated returns how many simultaneous disk failures a scheme survives: the parity count of
+// an erasure coding scheme such as "4+2", or the replica count minus one of a replication scheme such as
+// "3x".
+func failuresTolerated(scheme string) (int, bool) {
+	if match := ecSchemePattern.FindStringSubmatch(scheme); match != nil {
+		parity, err := strconv.Atoi(match[2])
+		return parity, err == nil
+	}
+	digits := strings.TrimFunc(scheme, func(r rune) bool { return r < '0' || r > '9' })
+	replicas, err := strconv.Atoi(digits)
+	if err != nil || replicas < 1 {
+		return 0, false
+	}
+	return replicas - 1, true
+}
+
+// DisksetRedundancy reports the redundancy scheme of every diskset and, when an expected scheme is
+// given, fails for disksets created with weaker redundancy (fewer tolerated failures) than the policy.
+func DisksetRedundancy(ctx context.Context, auth Utils.Authenticator, serviceIP string, expected string) CheckResult {
+	result := CheckResult{Name: "diskset-redundancy"}
+	parsedJSON, err := fetchJSON(ctx, auth, Utils.EndpointURL(serviceIP, "diskset"))
+	if err != nil {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to get disksets: %s", err)
+		return result
+	}
+	parsedJSONMap, ok := parsedJSON.(map[string]interface{})
+	if !ok {
+		result.Detail = "unexpected JSON structure: expected an object at the top level"
+		return result
+	}
+	disksets, _ := parsedJSONMap[Utils.Field("diskset", "disksets")].([]interface{})
+
+	expectedTolerance, expectedOK := failuresTolerated(expected)
+	if expected != "" && !expectedOK {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" unable to parse expected EC scheme '%s'", expected)
+		return result
+	}
+
+	schemes, weaker := []string{}, []string{}
+	for _, item := range disksets {
+		diskset, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		scheme := ""
+		for _, field := range ecSchemeFields {
+			if value, ok := diskset[field].(string); ok && value != "" {
+				scheme = value
+				break
+			}
+		}
+		if scheme == "" {
+			continue
+		}
+		disksetID := Utils.ID(diskset[Utils.Field("diskset", "id")])
+		Logger.Debugf(ctx, "Diskset ID: %v, Redundancy scheme: %s", disksetID, scheme)
+		schemes = append(schemes, fmt.Sprintf("%v=%s", disksetID, scheme))
+
+		if tolerance, ok := failuresTolerated(scheme); expected != "" && (!ok || tolerance < expectedTolerance) {
+			weaker = append(weaker, fmt.Sprintf("%v (%s)", disksetID, scheme))
+		}
+	}
+
+	if len(schemes) == 0 {
+		Logger.Print(ctx, Constants.SymbolWarn+" The diskset response does not expose a redundancy scheme, skipping redundancy check"+Constants.TwoNewLines)
+		result.OK = true
+		result.Status = StatusSkip
+		result.Detail = "redundancy scheme not exposed by the gateway"
+		return result
+	}
+
+	if len(weaker) > 0 {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" Disksets with weaker redundancy than the expected scheme %s: %s", expected, strings.Join(weaker, ", "))
+		return result
+	}
+
+	Logger.Print(ctx, Constants.SymbolOK+" Diskset redundancy schemes: "+strings.Join(schemes, ", ")+Constants.TwoNewLines)
+	result.OK = true
+	result.Detail = "diskset schemes: " + strings.Join(schemes, ", ")
+	return result
+}
+
+// CheckNetworkPolicies verifies that every required NetworkPolicy exists in the namespace. Deleting one can
+// break inter-pod traffic while the pods still report Ready. An empty list disables the check.
+func CheckNetworkPolicies(ctx context.Context, clientset *kubernetes.Clientset, namespace string, required []string) CheckResult {
+	result := CheckResult{Name: "network-policies"}
+	if len(required) == 0 {
+		Logger.Print(ctx, Constants.SymbolWarn+" No required NetworkPolicies configured, skipping NetworkPolicy check"+Constants.TwoNewLines)
+		result.OK = true
+		result.Status = StatusSkip
+		result.Detail = "no required NetworkPolicies configured"
+		return result
+	}
+
+	policies, err := clientset.NetworkingV1().NetworkPolicies(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to list NetworkPolicies in namespace %s: %s", namespace, err)
+		return result
+	}
+
+	present := make(map[string]bool)
+	for _, policy := range policies.Items {
+		present[policy.Name] = true
+	}
+
+	missing := []string{}
+	for _, name := range required {
+		if !present[name] {
+			Logger.Printf(ctx, Constants.SymbolFail+" NetworkPolicy '%s' is missing", name)
+			missing = append(missing, name)
+			continue
+		}
+		Logger.Debugf(ctx, Constants.SymbolOK+" NetworkPolicy '%s' is present", name)
+	}
+
+	if len(missing) > 0 {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" Required NetworkPolicies missing in namespace '%s': %s", namespace, strings.Join(missing, ", "))
+		return result
+	}
+
+	Logger.Print(ctx, Constants.SymbolOK+" All required NetworkPolicies are present"+Constants.TwoNewLines)
+	result.OK = true
+	result.Detail = "all required NetworkPolicies are present"
+	return result
+}
+
+// advertisedEndpointFields are the keys under which the gateway may self-report the address it advertises.
+var advertisedEndpointFields = []string{"advertised_endpoint", "gateway_endpoint", "endpoint", "external_ip"}
+
+// endpointHost strips any scheme, path and port from a self-reported endpoint.
+func endpointHost(endpoint string) string {
+	if u, err := neturl.Parse(endpoint); err == nil && u.Host != "" {
+		return u.Hostname()
+	}
+	if host, _, err := net.SplitHostPort(endpoint); err == nil {
+		return host
+	}
+	return endpoint
+}
+
+// AdvertisedEndpoint compares the service IP discovered from Kubernetes with any address the gateway
+// self-reports in /version or /node, and warns on a mismatch, which points at split DNS or a
+// misconfigured advertised address.
+func AdvertisedEndpoint(ctx context.Context, auth Utils.Authenticator, serviceIP string) CheckResult {
+	result := CheckResult{Name: "advertised-endpoint", OK: true}
+	advertised := map[string]string{}
+	collect := func(source string, object map[string]interface{}) {
+		for _, field := range advertisedEndpointFields {
+			if value, ok := object[field].(string); ok && value != "" {
+				advertised[endpointHost(value)] = source
+			}
+		}
+	}
+
+	if versionJSON, err := fetchJSON(ctx, auth, Utils.EndpointURL(serviceIP, "version")); err == nil {
+		if versionMap, ok := versionJSON.(map[string]interface{}); ok {
+			collect("/version", versionMap)
+		}
+	}
+	if nodesJSON, err := fetchJSON(ctx, auth, Utils.EndpointURL(serviceIP, "node")); err == nil {
+		if nodeList, ok := nodesJSON.([]interface{}); ok {
+			for _, item := range nodeList {
+				if nodeMap, ok := item.(map[string]interface{}); ok {
+					collect("/node", nodeMap)
+				}
+			}
+		}
+	}
+
+	if len(advertised) == 0 {
+		Logger.Print(ctx, Constants.SymbolWarn+" The gateway does not self-report an advertised endpoint, skipping comparison"+Constants.TwoNewLines)
+		result.Status = StatusSkip
+		result.Detail = "no self-reported endpoint"
+		return result
+	}
+
+	mismatched := []string{}
+	for address, source := range advertised {
+		if address != serviceIP {
+			mismatched = append(mismatched, fmt.Sprintf("%s (from %s)", address, source))
+		}
+	}
+	if len(mismatched) > 0 {
+		sort.Strings(mismatched)
+		Logger.Printf(ctx, Constants.SymbolWarn+" Gateway advertises %s but the Kubernetes service IP is %s; check for split DNS or a misconfigured advertised address", strings.Join(mismatched, ", "), serviceIP)
+		Logger.Print(ctx, Constants.TwoNewLines)
+		result.Status = StatusWarn
+		result.Detail = fmt.Sprintf("service IP %s, advertised %s", serviceIP, strings.Join(mismatched, ", "))
+		return result
+	}
+
+	Logger.Printf(ctx, Constants.SymbolOK+" Gateway advertised endpoint matches the service IP %s", serviceIP)
+	Logger.Print(ctx, Constants.TwoNewLines)
+	result.Detail = fmt.Sprintf("advertised endpoint matches service IP %s", serviceIP)
+	return result
+}
+
+// PodStaleness reports the age of the oldest running pod in the namespace and warns about pods created
+// before the Helm release was last deployed, since those were not restarted by the upgrade and may still
+// run with the previous configuration.
+func PodStaleness(ctx context.Context, clientset *kubernetes.Clientset, namespace string, rel *release.Release) CheckResult {
+	result := CheckResult{Name: "pod-staleness", OK: true}
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		result.OK = false
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to list pods in namespace %s: %s", namespace, err)
+		return result
+	}
+
+	lastDeployed := rel.Info.LastDeployed.Time
+	var oldest *v1.Pod
+	stale := []string{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		if oldest == nil || pod.CreationTimestamp.Before(&oldest.CreationTimestamp) {
+			oldest = pod
+		}
+		if pod.CreationTimestamp.Time.Before(lastDeployed) {
+			stale = append(stale, pod.Name)
+		}
+	}
+
+	if oldest == nil {
+		result.Detail = "no running pods"
+		return result
+	}
+
+	age := time.Since(oldest.CreationTimestamp.Time).Round(time.Second)
+	Logger.Printf(ctx, " Oldest pod: '%s', age %s; release '%s' last deployed %s", oldest.Name, age, rel.Name, lastDeployed.Format(time.RFC3339))
+	if len(stale) > 0 {
+		Logger.Printf(ctx, Constants.SymbolWarn+" %d pod(s) predate the last deployment of release '%s' and were not restarted by it: %s", len(stale), rel.Name, strings.Join(stale, ", "))
+		Logger.Print(ctx, Constants.TwoNewLines)
+		result.Status = StatusWarn
+		result.Detail = fmt.Sprintf("oldest pod %s (age %s); pods predating the last deployment: %s", oldest.Name, age, strings.Join(stale, ", "))
+		return result
+	}
+
+	Logger.Print(ctx, Constants.SymbolOK+" All pods were started after the last deployment of the release"+Constants.TwoNewLines)
+	result.Detail = fmt.Sprintf("oldest pod %s (age %s) started after the last deployment", oldest.Name, age)
+	return result
+}
+
+// UnauthenticatedAccess issues a request to an admin endpoint without a token and verifies the gateway
+// rejects it with 401 or 403. Returning data to an anonymous caller is a critical security
+// misconfiguration.
+func UnauthenticatedAccess(ctx context.Context, serviceIP string) CheckResult {
+	result := CheckResult{Name: "unauthenticated-access"}
+	url := Utils.EndpointURL(serviceIP, "node")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		result.Detail = fmt.Sprintf("failed to create request: %s", err)
+		return result
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-rakuten-internal", "user")
+	Utils.ApplyUserAgent(req)
+
+	resp, err := Utils.Do(req)
+	if err != nil {
+		result.Detail = fmt.Sprintf("failed to execute request: %s", err)
+		return result
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	Logger.Printf(ctx, " Unauthenticated request to %s returned: %s", url, resp.Status)
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		Logger.Print(ctx, Constants.SymbolOK+" Gateway rejects unauthenticated requests"+Constants.TwoNewLines)
+		result.OK = true
+		result.Detail = "unauthenticated request rejected with " + resp.Status
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" CRITICAL: gateway returned data to an unauthenticated request (%s)", resp.Status)
+	default:
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" unexpected response to an unauthenticated request: %s, expected 401 or 403", resp.Status)
+	}
+	return result
+}
+
+// CheckListBuckets verifies the data path end to end by listing the buckets through the gateway and
+// checking that the response is well formed. A cluster without any bucket is healthy.
+func CheckListBuckets(ctx context.Context, auth Utils.Authenticator, serviceIP string) CheckResult {
+	result := CheckResult{Name: "list-buckets"}
+	// An empty listing may come back as null rather than an empty array, which decodes to no buckets.
+	bucketList, err := Utils.GetAndDecode[[]map[string]interface{}](ctx, Utils.GetInsecureHTTPClient(), Utils.EndpointURL(serviceIP, "bucket"), auth)
+	var decodeErr *Utils.DecodeError
+	if errors.As(err, &decodeErr) {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" malformed bucket listing: %s", err)
+		return result
+	}
+	if err != nil {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to list buckets: %s", err)
+		return result
+	}
+
+	for i, bucket := range bucketList {
+		if name, ok := bucket[Utils.Field("bucket", "name")].(string); !ok || name == "" {
+			result.Detail = fmt.Sprintf(Constants.SymbolFail+" malformed bucket listing: entry %d has no name", i)
+			return result
+		}
+	}
+
+	Logger.Printf(ctx, Constants.SymbolOK+" Gateway listed %d buckets successfully%s", len(bucketList), Constants.TwoNewLines)
+	result.OK = true
+	result.Detail = fmt.Sprintf("%d buckets", len(bucketList))
+	return result
+}
+
+// probeFailureThreshold is the number of recorded probe failures above which a pod's probe is reported
+// as failing.
+const probeFailureThreshold = 5
+
+// PodProbes warns about required pods whose containers define no readiness or liveness probe, since
+// such a pod can report Ready before it serves traffic, and about their probes that keep failing
+// according to the namespace's Unhealthy events. It is a configuration quality check and never fails
+// the run.
+func PodProbes(ctx context.Context, clientset *kubernetes.Clientset, namespace string, requiredPodPrefixes []string) CheckResult {
+	result := CheckResult{Name: "pod-probes", OK: true}
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		result.OK = false
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to list pods in namespace %s: %s", namespace, err)
+		return result
+	}
+
+	required := func(podName string) bool {
+		return slices.ContainsFunc(requiredPodPrefixes, func(prefix string) bool { return strings.HasPrefix(podName, prefix) })
+	}
+	findings := []string{}
+	for _, pod := range pods.Items {
+		if !required(pod.Name) {
+			continue
+		}
+		for _, container := range pod.Spec.Containers {
+			missing := []string{}
+			if container.ReadinessProbe == nil {
+				missing = append(missing, "readiness")
+			}
+			if container.LivenessProbe == nil {
+				missing = append(missing, "liveness")
+			}
+			if len(missing) > 0 {
+				findings = append(findings, fmt.Sprintf("pod '%s' container '%s' has no %s probe", pod.Name, container.Name, strings.Join(missing, " or ")))
+			}
+		}
+	}
+
+	events, err := clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{FieldSelector: "reason=Unhealthy"})
+	if err != nil {
+		Logger.Printf(ctx, Constants.SymbolWarn+" Unable to list events in namespace %s, skipping probe failure counts: %v", namespace, err)
+	} else {
+		failures := map[string]int32{}
+		for _, event := range events.Items {
+			if event.InvolvedObject.Kind != "Pod" || !required(event.InvolvedObject.Name) {
+				continue
+			}
+			probe, _, found := strings.Cut(event.Message, " probe failed")
+			if !found {
+				continue
+			}
+			count := event.Count
+			if count == 0 {
+				count = 1
+			}
+			failures[event.InvolvedObject.Name+" "+strings.ToLower(probe)] += count
+		}
+		keys := make([]string, 0, len(failures))
+		for key := range failures {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if failures[key] > probeFailureThreshold {
+				podName, probe, _ := strings.Cut(key, " ")
+				findings = append(findings, fmt.Sprintf("pod '%s' %s probe failed %d times", podName, probe, failures[key]))
+			}
+		}
+	}
+
+	if len(findings) > 0 {
+		for _, finding := range findings {
+			Logger.Printf(ctx, Constants.SymbolWarn+" %s", finding)
+		}
+		Logger.Print(ctx, Constants.TwoNewLines)
+		result.Status = StatusWarn
+		result.Detail = strings.Join(findings, "; ")
+		return result
+	}
+
+	Logger.Print(ctx, Constants.SymbolOK+" All required pods define readiness and liveness probes and none are failing repeatedly"+Constants.TwoNewLines)
+	return result
+}
+
+// CheckResourceLabels verifies every Deployment and StatefulSet in the namespace carries the expected
+// labels, which config-management tooling relies on. An expected value of "" only requires the label to
+// be present. An empty expected set disables the check.
+func CheckResourceLabels(ctx context.Context, clientset *kubernetes.Clientset, namespace string, expected map[string]string) CheckResult {
+	result := CheckResult{Name: "resource-labels"}
+	if len(expected) == 0 {
+		Logger.Print(ctx, Constants.SymbolWarn+" No expected labels configured, skipping resource label check"+Constants.TwoNewLines)
+		result.OK = true
+		result.Status = StatusSkip
+		result.Detail = "no expected labels configured"
+		return result
+	}
+
+	deployments, err := clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to list deployments in namespace %s: %s", namespace, err)
+		return result
+	}
+	statefulSets, err := clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to list statefulsets in namespace %s: %s", namespace, err)
+		return result
+	}
+
+	resources := []metav1.ObjectMeta{}
+	kinds := []string{}
+	for _, deployment := range deployments.Items {
+		resources = append(resources, deployment.ObjectMeta)
+		kinds = append(kinds, "Deployment")
+	}
+	for _, statefulSet := range statefulSets.Items {
+		resources = append(resources, statefulSet.ObjectMeta)
+		kinds = append(kinds, "StatefulSet")
+	}
+
+	keys := make([]string, 0, len(expected))
+	for key := range expected {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	drift := []string{}
+	for i, meta := range resources {
+		for _, key := range keys {
+			value, found := meta.Labels[key]
+			switch {
+			case !found:
+				drift = append(drift, fmt.Sprintf("%s '%s' is missing label '%s'", kinds[i], meta.Name, key))
+			case expected[key] != "" && value != expected[key]:
+				drift = append(drift, fmt.Sprintf("%s '%s' has label '%s=%s', expected '%s'", kinds[i], meta.Name, key, value, expected[key]))
+			}
+		}
+	}
+
+	if len(drift) > 0 {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" Label drift on ostore resources: %s", strings.Join(drift, "; "))
+		return result
+	}
+
+	Logger.Printf(ctx, Constants.SymbolOK+" All %d ostore Deployments/StatefulSets carry the expected labels%s", len(resources), Constants.TwoNewLines)
+	result.OK = true
+	return result
+}
+
+// gatewayReplica is a ready gateway pod behind the service.
+type gatewayReplica struct {
+	name, address string
+}
+
+// readyGatewayReplicas lists the ready endpoints of the gateway service, named after their pod.
+func readyGatewayReplicas(ctx context.Context, clientset *kubernetes.Clientset, namespace, serviceName string) ([]gatewayReplica, error) {
+	endpointSlices, err := clientset.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: discoveryv1.LabelServiceName + "=" + serviceName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf(Constants.SymbolFail+" failed to list endpoints of service '%s': %s", serviceName, err)
+	}
+
+	replicas := []gatewayReplica{}
+	for _, slice := range endpointSlices.Items {
+		for _, endpoint := range slice.Endpoints {
+			if len(endpoint.Addresses) == 0 || endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready {
+				continue
+			}
+			name := endpoint.Addresses[0]
+			if endpoint.TargetRef != nil {
+				name = endpoint.TargetRef.Name
+			}
+			replicas = append(replicas, gatewayReplica{name, endpoint.Addresses[0]})
+		}
+	}
+	return replicas, nil
+}
+
+// GatewayConsistency queries /cluster_health on every ready gateway replica behind the service and fails
+// when they disagree on the cluster status, which points at a gateway that is out of sync. Replicas that
+// can't be reached directly, e.g. because pod IPs aren't routable from where the tool runs, are only
+// warned about.
+func GatewayConsistency(ctx context.Context, clientset *kubernetes.Clientset, auth Utils.Authenticator, namespace, serviceName string) CheckResult {
+	result := CheckResult{Name: "gateway-consistency"}
+	replicas, err := readyGatewayReplicas(ctx, clientset, namespace, serviceName)
+	if err != nil {
+		result.Detail = err.Error()
+		return result
+	}
+	if len(replicas) < 2 {
+		Logger.Printf(ctx, Constants.SymbolInfo+" Service '%s' has %d ready gateway replica(s), skipping consistency check%s", serviceName, len(replicas), Constants.TwoNewLines)
+		result.OK = true
+		result.Status = StatusSkip
+		result.Detail = fmt.Sprintf("%d ready gateway replica(s)", len(replicas))
+		return result
+	}
+
+	statuses := map[string]string{}
+	perReplica, unreachable := []string{}, []string{}
+	for _, r := range replicas {
+		parsedJSON, err := fetchJSON(ctx, auth, Utils.EndpointURL(r.address, "cluster_health"))
+		if err != nil {
+			Logger.Printf(ctx, Constants.SymbolWarn+" Gateway replica '%s' (%s) could not be queried: %v", r.name, r.address, err)
+			unreachable = append(unreachable, r.name)
+			continue
+		}
+		health, _ := parsedJSON.(map[string]interface{})
+		status := fmt.Sprint(health[Utils.Field("cluster_health", "clusterHealthStatus")])
+		Logger.Debugf(ctx, "Gateway replica '%s' (%s) reports cluster status %s", r.name, r.address, status)
+		statuses[status] = r.name
+		perReplica = append(perReplica, fmt.Sprintf("%s=%s", r.name, status))
+	}
+
+	if len(statuses) > 1 {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" Gateway replicas disagree on the cluster status: %s", strings.Join(perReplica, ", "))
+		return result
+	}
+
+	result.OK = true
+	if len(unreachable) > 0 {
+		Logger.Print(ctx, Constants.TwoNewLines)
+		result.Status = StatusWarn
+		result.Detail = fmt.Sprintf("replicas %s could not be queried; reachable replicas: %s", strings.Join(unreachable, ", "), strings.Join(perReplica, ", "))
+		return result
+	}
+	Logger.Print(ctx, Constants.SymbolOK+" All gateway replicas agree on the cluster status"+Constants.TwoNewLines)
+	result.Detail = strings.Join(perReplica, ", ")
+	return result
+}
+
+// TLSSubjectAltNames verifies the certificate served by the gateway lists the endpoint the tool connects
+// to among its subject alternative names, since strict TLS clients reject the connection otherwise. It
+// reports the certificate's actual SANs on mismatch.
+func TLSSubjectAltNames(ctx context.Context, serviceIP string) CheckResult {
+	result := CheckResult{Name: "tls-san"}
+	u, err := neturl.Parse(Utils.EndpointURL(serviceIP, "version"))
+	if err != nil {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to build gateway address: %s", err)
+		return result
+	}
+
+	// Verification is done by hand below so a mismatch can be reported with the SANs the cert does carry.
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: 5 * time.Second}, "tcp", u.Host, &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" TLS handshake with %s failed: %s", u.Host, err)
+		return result
+	}
+	defer conn.Close()
+
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" gateway at %s presented no certificate", u.Host)
+		return result
+	}
+	leaf := certs[0]
+	sans := append([]string{}, leaf.DNSNames...)
+	for _, ip := range leaf.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	Logger.Printf(ctx, " Gateway certificate SANs: %s", strings.Join(sans, ", "))
+
+	if err := leaf.VerifyHostname(u.Hostname()); err != nil {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" gateway certificate does not cover %s; SANs: [%s]", u.Hostname(), strings.Join(sans, ", "))
+		return result
+	}
+
+	Logger.Printf(ctx, Constants.SymbolOK+" Gateway certificate SANs cover %s%s", u.Hostname(), Constants.TwoNewLines)
+	result.OK = true
+	result.Detail = fmt.Sprintf("SANs cover %s", u.Hostname())
+	return result
+}
+
+// CertificateExpiry warns when the certificate served by the gateway expires within warnWithin and fails
+// once it has expired, since the tool skips verification by default and would otherwise not notice
+// until clients start rejecting the gateway.
+func CertificateExpiry(ctx context.Context, serviceIP string, warnWithin time.Duration) CheckResult {
+	result := CheckResult{Name: "certificate-expiry"}
+	u, err := neturl.Parse(Utils.EndpointURL(serviceIP, "version"))
+	if err != nil {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to build gateway address: %s", err)
+		return result
+	}
+
+	dialer := &tls.Dialer{NetDialer: &net.Dialer{Timeout: 5 * time.Second}, Config: &tls.Config{InsecureSkipVerify: true}}
+	conn, err := dialer.DialContext(ctx, "tcp", u.Host)
+	if err != nil {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" TLS handshake with %s failed: %s", u.Host, err)
+		result.Err = err
+		return result
+	}
+	defer conn.Close()
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" gateway at %s presented no certificate", u.Host)
+		return result
+	}
+	leaf := certs[0]
+	remaining := time.Until(leaf.NotAfter)
+	days := int(math.Floor(remaining.Hours() / 24))
+	notAfter := leaf.NotAfter.UTC().Format(time.RFC3339)
+	result.Data = map[string]interface{}{"not_after": notAfter, "days_remaining": days, "subject": leaf.Subject.String()}
+	Logger.Printf(ctx, " Gateway certificate: subject %s, expires %s (%d days)", leaf.Subject, notAfter, days)
+
+	switch {
+	case remaining <= 0:
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" gateway certificate expired on %s (%d days ago)", notAfter, -days)
+		return result
+	case remaining < warnWithin:
+		Logger.Printf(ctx, Constants.SymbolWarn+" Gateway certificate expires within %s%s", warnWithin, Constants.TwoNewLines)
+		result.OK = true
+		result.Status = StatusWarn
+		result.Detail = fmt.Sprintf(Constants.SymbolWarn+" gateway certificate expires on %s, in %d days", notAfter, days)
+		return result
+	}
+
+	Logger.Printf(ctx, Constants.SymbolOK+" Gateway certificate is valid for %d more days%s", days, Constants.TwoNewLines)
+	result.OK = true
+	result.Detail = fmt.Sprintf("certificate expires on %s, in %d days", notAfter, days)
+	return result
+}
+
+// GatewayReplicas fails when fewer than minReplicas ready gateway pods sit behind the service, since a
+// single gateway is a single point of failure even when every other check passes.
+func GatewayReplicas(ctx context.Context, clientset *kubernetes.Clientset, namespace, serviceName string, minReplicas int) CheckResult {
+	result := CheckResult{Name: "gateway-replicas"}
+	replicas, err := readyGatewayReplicas(ctx, clientset, namespace, serviceName)
+	if err != nil {
+		result.Detail = err.Error()
+		return result
+	}
+
+	names := make([]string, 0, len(replicas))
+	for _, r := range replicas {
+		names = append(names, r.name)
+	}
+	Logger.Printf(ctx, " Ready gateway endpoints behind '%s': %d %v", serviceName, len(replicas), names)
+	if len(replicas) < minReplicas {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" service '%s' has %d ready gateway endpoint(s), expected at least %d", serviceName, len(replicas), minReplicas)
+		return result
+	}
+
+	Logger.Printf(ctx, Constants.SymbolOK+" %d ready gateway endpoint(s), at least %d required%s", len(replicas), minReplicas, Constants.TwoNewLines)
+	result.OK = true
+	result.Detail = fmt.Sprintf("%d ready gateway endpoints", len(replicas))
+	return result
+}
+
+// backupLastSuccessFields are the keys the /backup response may use for the last successful backup.
+var backupLastSuccessFields = []string{"last_success", "last_successful_backup", "last_backup_time", "lastBackupTime"}
+
+// BackupSchedule verifies backups are enabled with a schedule and that the last successful backup is no
+// older than maxAge. Gateways without a backup endpoint skip the check.
+func BackupSchedule(ctx context.Context, auth Utils.Authenticator, serviceIP string, maxAge time.Duration) CheckResult {
+	result := CheckResult{Name: "backup"}
+	backup, err := Utils.GetAndDecode[map[string]interface{}](ctx, Utils.GetInsecureHTTPClient(), Utils.EndpointURL(serviceIP, "backup"), auth)
+	var statusErr *Utils.StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+		Logger.Print(ctx, Constants.SymbolInfo+" The gateway does not expose a backup endpoint, skipping backup check"+Constants.TwoNewLines)
+		result.OK = true
+		result.Status = StatusSkip
+		result.Detail = "backup endpoint not exposed"
+		return result
+	}
+	if err != nil {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to get backup configuration: %s", err)
+		return result
+	}
+
+	if enabled, ok := backup[Utils.Field("backup", "enabled")].(bool); ok && !enabled {
+		result.Detail = Constants.SymbolFail + " Backups are disabled"
+		return result
+	}
+	schedule, _ := backup[Utils.Field("backup", "schedule")].(string)
+	if schedule == "" {
+		result.Detail = Constants.SymbolFail + " No backup schedule is configured"
+		return result
+	}
+	Logger.Printf(ctx, " Backup schedule: %s", schedule)
+
+	var lastSuccess time.Time
+	for _, field := range backupLastSuccessFields {
+		switch value := backup[field].(type) {
+		case string:
+			lastSuccess, _ = time.Parse(time.RFC3339, value)
+		case float64:
+			lastSuccess = time.Unix(int64(value), 0)
+		}
+		if !lastSuccess.IsZero() {
+			break
+		}
+	}
+	if lastSuccess.IsZero() {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" Backup schedule '%s' is configured but no successful backup is recorded", schedule)
+		return result
+	}
+
+	age := time.Since(lastSuccess).Round(time.Second)
+	Logger.Printf(ctx, " Last successful backup: %s (%s ago)", lastSuccess.Format(time.RFC3339), age)
+	if maxAge > 0 && age > maxAge {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" Last successful backup at %s is %s old, more than the allowed %s", lastSuccess.Format(time.RFC3339), age, maxAge)
+		return result
+	}
+
+	Logger.Print(ctx, Constants.SymbolOK+" Backups are scheduled and recent"+Constants.TwoNewLines)
+	result.OK = true
+	result.Detail = fmt.Sprintf("schedule '%s', last successful backup %s", schedule, lastSuccess.Format(time.RFC3339))
+	return result
+}
+
+// requiredAccess lists the verbs and resources the checks use. An empty namespace means cluster scope,
+// "*" the ostore namespace and kube-system the configured control-plane namespace.
+var requiredAccess = []authorizationv1.ResourceAttributes{
+	{Verb: "list", Resource: "componentstatuses"},
+	{Verb: "list", Resource: "nodes"},
+	{Verb: "list", Resource: "persistentvolumes"},
+	{Verb: "list", Resource: "secrets"}, // Helm stores its releases in secrets across namespaces
+	{Verb: "list", Resource: "pods", Namespace: kubeSystemNamespace},
+	{Verb: "list", Resource: "pods", Namespace: "*"},
+	{Verb: "get", Resource: "pods", Subresource: "proxy", Namespace: "*"},
+	{Verb: "get", Resource: "services", Namespace: "*"},
+	{Verb: "list", Resource: "events", Namespace: "*"},
+	{Verb: "get", Resource: "jobs", Group: "batch", Namespace: "*"},
+	{Verb: "list", Resource: "deployments", Group: "apps", Namespace: "*"},
+	{Verb: "list", Resource: "statefulsets", Group: "apps", Namespace: "*"},
+	{Verb: "get", Resource: "controllerrevisions", Group: "apps", Namespace: "*"},
+	{Verb: "list", Resource: "endpointslices", Group: "discovery.k8s.io", Namespace: "*"},
+	{Verb: "list", Resource: "networkpolicies", Group: "networking.k8s.io", Namespace: "*"},
+}
+
+// RBACPreflight asks the API server, through SelfSubjectAccessReviews, whether the current identity may
+// perform everything the checks need in namespace, and reports every missing permission at once instead
+// of letting them surface as confusing errors mid-run.
+func RBACPreflight(ctx context.Context, clientset *kubernetes.Clientset, namespace, controlPlaneNamespace string) CheckResult {
+	result := CheckResult{Name: "rbac"}
+	missing := []string{}
+	for _, access := range requiredAccess {
+		switch access.Namespace {
+		case "*":
+			access.Namespace = namespace
+		case kubeSystemNamespace:
+			access.Namespace = controlPlaneNamespace
+		}
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &access},
+		}
+		response, err := clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to review access: %s", err)
+			return result
+		}
+		if response.Status.Allowed {
+			continue
+		}
+
+		resource := access.Resource
+		if access.Subresource != "" {
+			resource += "/" + access.Subresource
+		}
+		if access.Group != "" {
+			resource += "." + access.Group
+		}
+		scope := "cluster-wide"
+		if access.Namespace != "" {
+			scope = "in namespace " + access.Namespace
+		}
+		missing = append(missing, fmt.Sprintf("%s %s %s", access.Verb, resource, scope))
+	}
+
+	if len(missing) > 0 {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" The service account is missing permissions the checks need, grant them before re-running: %s", strings.Join(missing, "; "))
+		return result
+	}
+
+	Logger.Print(ctx, Constants.SymbolOK+" The service account has every permission the checks need"+Constants.TwoNewLines)
+	result.OK = true
+	return result
+}
+
+// ybMasterHTTPPort is the port of the yb-master web server that serves the health API.
+const ybMasterHTTPPort = "7000"
+
+// YugabyteTablets asks a yb-master, through the API server's pod proxy, for the metadata store's tablet
+// health and fails when any tablet is under-replicated or has no leader. Masters without the health API
+// skip the check.
+func YugabyteTablets(ctx context.Context, clientset *kubernetes.Clientset, namespace string) CheckResult {
+	result := CheckResult{Name: "yugabyte-tablets"}
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to list pods in namespace %s: %s", namespace, err)
+		return result
+	}
+	master := ""
+	for _, pod := range pods.Items {
+		if strings.HasPrefix(pod.Name, "yb-master") && pod.Status.Phase == v1.PodRunning {
+			master = pod.Name
+			break
+		}
+	}
+	if master == "" {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" no running yb-master pod found in namespace %s", namespace)
+		return result
+	}
+
+	body, err := clientset.CoreV1().Pods(namespace).ProxyGet("http", master, ybMasterHTTPPort, "/api/v1/health-check", nil).DoRaw(ctx)
+	if err != nil {
+		Logger.Printf(ctx, Constants.SymbolInfo+" yb-master '%s' health API unavailable (%v), skipping tablet check%s", master, err, Constants.TwoNewLines)
+		result.OK = true
+		result.Status = StatusSkip
+		result.Detail = "yb-master health API unavailable"
+		return result
+	}
+	parsedJSON, err := Utils.ParseJSON(body)
+	if err != nil {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to parse yb-master health response: %s", err)
+		return result
+	}
+	health, ok := parsedJSON.(map[string]interface{})
+	if !ok {
+		result.Detail = "unexpected JSON structure: expected an object at the top level of the yb-master health response"
+		return result
+	}
+
+	underReplicated, _ := health["under_replicated_tablets"].([]interface{})
+	leaderless, _ := health["leaderless_tablets"].([]interface{})
+	Logger.Printf(ctx, " yb-master '%s': %d under-replicated, %d leaderless tablets", master, len(underReplicated), len(leaderless))
+	if len(underReplicated) > 0 || len(leaderless) > 0 {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" Metadata store has %d under-replicated and %d leaderless tablets", len(underReplicated), len(leaderless))
+		return result
+	}
+
+	Logger.Print(ctx, Constants.SymbolOK+" All metadata store tablets are fully replicated and have a leader"+Constants.TwoNewLines)
+	result.OK = true
+	result.Detail = "no under-replicated or leaderless tablets"
+	return result
+}
+
+// uptimeFields are the keys the admin API may use for the cluster's uptime in seconds, and startTimeFields
+// for the time it started.
+var (
+	uptimeFields    = []string{"uptime", "uptime_seconds", "uptimeSeconds"}
+	startTimeFields = []string{"start_time", "started_at", "startTime", "boot_time"}
+)
+
+// clusterStartTime looks for a start time or an uptime in an admin API response.
+func clusterStartTime(response map[string]interface{}) (time.Time, bool) {
+	for _, field := range startTimeFields {
+		switch value := response[field].(type) {
+		case string:
+			if started, err := time.Parse(time.RFC3339, value); err == nil {
+				return started, true
+			}
+		case float64:
+			return time.Unix(int64(value), 0), true
+		}
+	}
+	for _, field := range uptimeFields {
+		if seconds, ok := Utils.Int64(response[field]); ok {
+			return time.Now().Add(-time.Duration(seconds) * time.Second), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// ClusterUptime reports when the cluster last started, taken from the cluster health or version
+// responses, and warns when it restarted within recentWindow since checks may then run against a
+// cluster that is still stabilizing. Gateways that don't expose it skip the check.
+func ClusterUptime(ctx context.Context, auth Utils.Authenticator, serviceIP string, recentWindow time.Duration) CheckResult {
+	result := CheckResult{Name: "uptime", OK: true}
+	var started time.Time
+	found := false
+	for _, endpoint := range []string{"cluster_health", "version"} {
+		parsedJSON, err := fetchJSON(ctx, auth, Utils.EndpointURL(serviceIP, endpoint))
+		if err != nil {
+			continue
+		}
+		if response, ok := parsedJSON.(map[string]interface{}); ok {
+			if started, found = clusterStartTime(response); found {
+				break
+			}
+		}
+	}
+	if !found {
+		Logger.Print(ctx, Constants.SymbolInfo+" The admin API does not expose an uptime or start time, skipping uptime check"+Constants.TwoNewLines)
+		result.Status = StatusSkip
+		result.Detail = "uptime not exposed"
+		return result
+	}
+
+	uptime := time.Since(started).Round(time.Second)
+	Logger.Printf(ctx, " Cluster started at %s, up %s", started.Format(time.RFC3339), uptime)
+	result.Detail = fmt.Sprintf("started %s, up %s", started.Format(time.RFC3339), uptime)
+	if recentWindow > 0 && uptime < recentWindow {
+		Logger.Printf(ctx, Constants.SymbolWarn+" The cluster restarted within the last %s and may still be stabilizing%s", recentWindow, Constants.TwoNewLines)
+		result.Status = StatusWarn
+		result.Detail = fmt.Sprintf("restarted %s ago, within the %s window; %s", uptime, recentWindow, result.Detail)
+		return result
+	}
+	Logger.Print(ctx, Constants.TwoNewLines)
+	return result
+}
+
+// disksetNodes returns the nodes a diskset has disks on, read from the diskset itself or else from its
+// member disks.
+func disksetNodes(diskset map[string]interface{}) []string {
+	for _, field := range diskNodeFields {
+		if node, ok := diskset[field].(string); ok && node != "" {
+			return []string{node}
+		}
+	}
+	nodes := []string{}
+	for _, field := range disksetMemberFields {
+		members, ok := diskset[field].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, item := range members {
+			member, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for _, nodeField := range diskNodeFields {
+				if node, ok := member[nodeField].(string); ok && node != "" {
+					if !slices.Contains(nodes, node) {
+						nodes = append(nodes, node)
+					}
+					break
+				}
+			}
+		}
+		break
+	}
+	return nodes
+}
+
+// DisksetDistribution cross-references /node and /diskset to count the disksets on every active node
+// and warns when the distribution is skewed: when the least loaded node holds more than maxImbalancePct
+// percent fewer disksets than the most loaded one. The check is skipped when the disksets don't expose
+// the nodes they live on.
+func DisksetDistribution(ctx context.Context, auth Utils.Authenticator, serviceIP string, maxImbalancePct float64) CheckResult {
+	result := CheckResult{Name: "diskset-distribution"}
+	nodesJSON, err := fetchJSON(ctx, auth, Utils.EndpointURL(serviceIP, "node"))
+	if err != nil {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to get nodes: %s", err)
+		return result
+	}
+	disksetsJSON, err := fetchJSON(ctx, auth, Utils.EndpointURL(serviceIP, "diskset"))
+	if err != nil {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to get disksets: %s", err)
+		return result
+	}
+
+	nodeList, ok := nodesJSON.([]interface{})
+	if !ok {
+		result.Detail = fmt.Sprintf("unexpected JSON structure: expected an array of nodes, but got %T", nodesJSON)
+		return result
+	}
+	disksetMap, ok := disksetsJSON.(map[string]interface{})
+	if !ok {
+		result.Detail = "unexpected JSON structure: expected an object at the top level of the diskset response"
+		return result
+	}
+	disksets, _ := disksetMap[Utils.Field("diskset", "disksets")].([]interface{})
+
+	// counts holds the disksets per node, starting every active node at zero so an empty node shows up.
+	counts := map[string]int{}
+	for _, item := range nodeList {
+		node, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := node[Utils.Field("node", "name")].(string)
+		status, _ := node[Utils.Field("node", "status_str")].(string)
+		if name != "" && !slices.Contains(zombieNodeStates, status) {
+			counts[name] = 0
+		}
+	}
+	located := false
+	for _, item := range disksets {
+		diskset, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, node := range disksetNodes(diskset) {
+			located = true
+			counts[node]++
+		}
+	}
+
+	if !located {
+		Logger.Print(ctx, Constants.SymbolInfo+" The diskset response does not expose the nodes disksets live on, skipping distribution check"+Constants.TwoNewLines)
+		result.OK = true
+		result.Status = StatusSkip
+		result.Detail = "diskset placement not exposed by the gateway"
+		return result
+	}
+
+	nodes := make([]string, 0, len(counts))
+	for node := range counts {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	perNode := []string{}
+	least, most := counts[nodes[0]], counts[nodes[0]]
+	for _, node := range nodes {
+		Logger.Debugf(ctx, "Node: %s | Disksets: %d", node, counts[node])
+		perNode = append(perNode, fmt.Sprintf("%s=%d", node, counts[node]))
+		least, most = min(least, counts[node]), max(most, counts[node])
+	}
+
+	result.OK = true
+	result.Detail = "disksets per node: " + strings.Join(perNode, ", ")
+	imbalance := float64(most-least) / float64(most) * 100
+	if imbalance > maxImbalancePct {
+		Logger.Printf(ctx, Constants.SymbolWarn+" Disksets are unevenly distributed: %d to %d per node (%.0f%% imbalance, limit %.0f%%)%s", least, most, imbalance, maxImbalancePct, Constants.TwoNewLines)
+		result.Status = StatusWarn
+		result.Detail = fmt.Sprintf("disksets unevenly distributed (%.0f%% imbalance, limit %.0f%%): %s", imbalance, maxImbalancePct, strings.Join(perNode, ", "))
+		return result
+	}
+	Logger.Print(ctx, Constants.SymbolOK+" Disksets are evenly distributed across nodes"+Constants.TwoNewLines)
+	return result
+}
+
+// PodQoS warns about required pods in the BestEffort QoS class. Such pods set no resource requests or
+// limits and are the first the kubelet evicts under node pressure, so critical components should be
+// Guaranteed or at least Burstable. It is a resilience configuration check and never fails the run.
+func PodQoS(ctx context.Context, clientset *kubernetes.Clientset, namespace string, requiredPodPrefixes []string) CheckResult {
+	result := CheckResult{Name: "pod-qos", OK: true}
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		result.OK = false
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to list pods in namespace %s: %s", namespace, err)
+		return result
+	}
+
+	bestEffort := []string{}
+	for _, pod := range pods.Items {
+		if !slices.ContainsFunc(requiredPodPrefixes, func(prefix string) bool { return strings.HasPrefix(pod.Name, prefix) }) {
+			continue
+		}
+		Logger.Debugf(ctx, "Pod: %s | QoS: %s", pod.Name, pod.Status.QOSClass)
+		if pod.Status.QOSClass == v1.PodQOSBestEffort {
+			bestEffort = append(bestEffort, fmt.Sprintf("pod '%s' has QoS %s", pod.Name, pod.Status.QOSClass))
+		}
+	}
+
+	if len(bestEffort) > 0 {
+		for _, finding := range bestEffort {
+			Logger.Printf(ctx, Constants.SymbolWarn+" %s", finding)
+		}
+		Logger.Print(ctx, Constants.TwoNewLines)
+		result.Status = StatusWarn
+		result.Detail = "critical pods without resource requests or limits: " + strings.Join(bestEffort, "; ")
+		return result
+	}
+
+	Logger.Print(ctx, Constants.SymbolOK+" No required pod runs with BestEffort QoS"+Constants.TwoNewLines)
+	return result
+}
+
+// upgradeFlagFields are the keys the admin API may use to flag an upgrade or maintenance in progress,
+// and upgradeStateFields those describing it, e.g. "UPGRADING" or "MAINTENANCE".
+var (
+	upgradeFlagFields  = []string{"upgrade_in_progress", "upgrading", "maintenance_mode", "in_maintenance"}
+	upgradeStateFields = []string{"upgrade_state", "upgrade_status", "maintenance_state"}
+)
+
+// idleUpgradeStates are the upgrade state values that mean no upgrade is running.
+var idleUpgradeStates = []string{"", "NONE", "IDLE", "COMPLETED", "DONE", "NOT_STARTED"}
+
+// UpgradeState reports whether the cluster is in an upgrade or maintenance window, taken from the
+// cluster health or version responses, and describes the state. exposed is false when neither response
+// carries any upgrade information.
+func UpgradeState(ctx context.Context, auth Utils.Authenticator, serviceIP string) (state string, upgrading bool, exposed bool) {
+	for _, endpoint := range []string{"cluster_health", "version"} {
+		parsedJSON, err := fetchJSON(ctx, auth, Utils.EndpointURL(serviceIP, endpoint))
+		if err != nil {
+			continue
+		}
+		response, ok := parsedJSON.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, field := range upgradeStateFields {
+			if value, ok := response[field].(string); ok {
+				return value, !slices.Contains(idleUpgradeStates, strings.ToUpper(value)), true
+			}
+		}
+		for _, field := range upgradeFlagFields {
+			if value, ok := response[field].(bool); ok {
+				if value {
+					return field, true, true
+				}
+				return "", false, true
+			}
+		}
+	}
+	return "", false, false
+}
+
+// LoadBurst configures GatewayUnderLoad: how many concurrent requests to fire and the largest share of
+// them, in percent, that may fail.
+type LoadBurst struct {
+	Requests    int
+	MaxErrorPct float64
+}
+
+// GatewayUnderLoad fires a burst of concurrent authenticated requests at the version endpoint and
+// reports the success rate and latency distribution, failing when the error rate exceeds the limit. It
+// surfaces concurrency-related instability that a single sequential probe won't reveal.
+func GatewayUnderLoad(ctx context.Context, auth Utils.Authenticator, serviceIP string, burst LoadBurst) CheckResult {
+	result := CheckResult{Name: "load"}
+	url := Utils.EndpointURL(serviceIP, "version")
+
+	latencies := make([]time.Duration, burst.Requests)
+	failures := make([]string, burst.Requests)
+	var wg sync.WaitGroup
+	for i := 0; i < burst.Requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+			if err != nil {
+				failures[i] = err.Error()
+				return
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("x-rakuten-internal", "user")
+			start := time.Now()
+			resp, err := Utils.DoAuthenticated(req, auth)
+			if err != nil {
+				failures[i] = err.Error()
+				return
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			latencies[i] = time.Since(start)
+			if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+				failures[i] = resp.Status
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	succeeded := []time.Duration{}
+	errorCounts := map[string]int{}
+	for i := range failures {
+		if failures[i] != "" {
+			errorCounts[failures[i]]++
+			continue
+		}
+		succeeded = append(succeeded, latencies[i])
+	}
+	sort.Slice(succeeded, func(i, j int) bool { return succeeded[i] < succeeded[j] })
+
+	errorPct := float64(burst.Requests-len(succeeded)) / float64(burst.Requests) * 100
+	Logger.Printf(ctx, " %d of %d concurrent requests succeeded (%.1f%% errors)", len(succeeded), burst.Requests, errorPct)
+	for failure, count := range errorCounts {
+		Logger.Printf(ctx, Constants.SymbolWarn+" %d requests failed: %s", count, failure)
+	}
+	result.Detail = fmt.Sprintf("%d/%d succeeded", len(succeeded), burst.Requests)
+	if len(succeeded) > 0 {
+		nearestRank := func(p int) time.Duration { return succeeded[max((p*len(succeeded)+99)/100, 1)-1] }
+		distribution := fmt.Sprintf("min %s, median %s, p95 %s, max %s", succeeded[0].Round(time.Millisecond),
+			nearestRank(50).Round(time.Millisecond), nearestRank(95).Round(time.Millisecond), succeeded[len(succeeded)-1].Round(time.Millisecond))
+		Logger.Print(ctx, " Latency: "+distribution)
+		result.Detail += "; latency " + distribution
+	}
+
+	if errorPct > burst.MaxErrorPct {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" %.1f%% of %d concurrent requests failed, above the allowed %.1f%% (%s)", errorPct, burst.Requests, burst.MaxErrorPct, result.Detail)
+		return result
+	}
+	Logger.Print(ctx, Constants.SymbolOK+" The gateway stayed healthy under concurrent load"+Constants.TwoNewLines)
+	result.OK = true
+	return result
+}
+
+// TimeSyncDaemonSet verifies the time-sync DaemonSet (e.g. chrony or ntp) has a ready pod on every node
+// it should run on, since a broken time daemon is a common cause of clock skew. An empty name disables
+// the check.
+func TimeSyncDaemonSet(ctx context.Context, clientset *kubernetes.Clientset, namespace, name string) CheckResult {
+	result := CheckResult{Name: "time-sync"}
+	if name == "" {
+		Logger.Print(ctx, Constants.SymbolInfo+" No --time-sync-daemonset configured, skipping time-sync DaemonSet check"+Constants.TwoNewLines)
+		result.OK = true
+		result.Status = StatusSkip
+		result.Detail = "no time-sync DaemonSet configured"
+		return result
+	}
+
+	daemonSet, err := clientset.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to get time-sync DaemonSet %s/%s: %s", namespace, name, err)
+		return result
+	}
+	desired, ready := daemonSet.Status.DesiredNumberScheduled, daemonSet.Status.NumberReady
+	Logger.Printf(ctx, " DaemonSet: %s/%s | Ready: %d/%d", namespace, name, ready, desired)
+	result.Detail = fmt.Sprintf("DaemonSet %s/%s has %d/%d pods ready", namespace, name, ready, desired)
+
+	if desired == 0 || ready < desired {
+		result.Detail = Constants.SymbolFail + " " + result.Detail + ", nodes without a ready time daemon may drift"
+		return result
+	}
+	Logger.Print(ctx, Constants.SymbolOK+" The time-sync DaemonSet is ready on every node"+Constants.TwoNewLines)
+	result.OK = true
+	return result
+}
+
+// ChartUpToDate compares the release's chart version against the known chart versions and warns when a
+// newer one is available, to help plan upgrades. It is purely advisory: it never fails, and it is skipped
+// when no versions are known.
+func ChartUpToDate(ctx context.Context, rel *release.Release, known []string) CheckResult {
+	result := CheckResult{Name: "chart-version", OK: true}
+	if len(known) == 0 {
+		Logger.Print(ctx, Constants.SymbolInfo+" No --latest-chart-versions configured, skipping chart version advisory"+Constants.TwoNewLines)
+		result.Status = StatusSkip
+		result.Detail = "no latest chart version configured"
+		return result
+	}
+
+	current, err := version.ParseGeneric(rel.Chart.Metadata.Version)
+	if err != nil {
+		Logger.Printf(ctx, Constants.SymbolWarn+" Unable to parse chart version '%s', skipping chart version advisory%s", rel.Chart.Metadata.Version, Constants.TwoNewLines)
+		result.Status = StatusSkip
+		result.Detail = fmt.Sprintf("unparsable chart version %s", rel.Chart.Metadata.Version)
+		return result
+	}
+	var latest *version.Version
+	for _, candidate := range known {
+		parsed, err := version.ParseGeneric(candidate)
+		if err != nil {
+			Logger.Printf(ctx, Constants.SymbolWarn+" Ignoring unparsable chart version '%s'", candidate)
+			continue
+		}
+		if latest == nil || latest.LessThan(parsed) {
+			latest = parsed
+		}
+	}
+	if latest == nil {
+		result.Status = StatusSkip
+		result.Detail = "no parsable latest chart version configured"
+		return result
+	}
+
+	Logger.Printf(ctx, " Chart: %s | Current: %s | Latest: %s", rel.Chart.Name(), current, latest)
+	result.Detail = fmt.Sprintf("current %s, latest %s", current, latest)
+	if current.LessThan(latest) {
+		Logger.Printf(ctx, Constants.SymbolWarn+" A newer chart version %s is available (current %s)%s", latest, current, Constants.TwoNewLines)
+		result.Status = StatusWarn
+		result.Detail = "newer chart available: " + result.Detail
+		return result
+	}
+	Logger.Print(ctx, Constants.SymbolOK+" The chart is at the latest known version"+Constants.TwoNewLines)
+	return result
+}
+
+// clusterIDFields are the keys the admin API may use for the cluster's UUID.
+var clusterIDFields = []string{"cluster_id", "cluster_uuid", "clusterId", "ClusterID", "ClusterId", "uuid"}
+
+// ClusterIdentity fetches the cluster ID from the admin endpoints and the gateway's replication endpoint
+// and fails when they differ, which means serviceIP reaches parts of two different clusters, e.g. a
+// misconfigured address pointing at a neighbouring cluster. The check is skipped when fewer than two
+// endpoints report an ID.
+func ClusterIdentity(ctx context.Context, auth Utils.Authenticator, serviceIP string) CheckResult {
+	result := CheckResult{Name: "cluster-identity"}
+	seen := map[string]string{}
+	endpoints := []string{}
+	for _, endpoint := range []string{"cluster_health", "version", "replication"} {
+		parsedJSON, err := fetchJSON(ctx, auth, Utils.EndpointURL(serviceIP, endpoint))
+		if err != nil {
+			continue
+		}
+		response, ok := parsedJSON.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, field := range clusterIDFields {
+			if id := Utils.ID(response[field]); response[field] != nil && id != "" {
+				Logger.Debugf(ctx, "Endpoint: %s | Cluster ID: %s", endpoint, id)
+				seen[endpoint] = id
+				endpoints = append(endpoints, endpoint)
+				break
+			}
+		}
+	}
+
+	if len(seen) < 2 {
+		Logger.Print(ctx, Constants.SymbolInfo+" Fewer than two endpoints report a cluster ID, skipping cluster identity check"+Constants.TwoNewLines)
+		result.OK = true
+		result.Status = StatusSkip
+		result.Detail = "cluster ID not exposed by enough endpoints"
+		return result
+	}
+
+	reported := []string{}
+	distinct := map[string]bool{}
+	for _, endpoint := range endpoints {
+		reported = append(reported, fmt.Sprintf("%s=%s", endpoint, seen[endpoint]))
+		distinct[seen[endpoint]] = true
+	}
+	if len(distinct) > 1 {
+		result.Detail = Constants.SymbolFail + " endpoints report different cluster IDs, the service IP may point at another cluster: " + strings.Join(reported, ", ")
+		return result
+	}
+
+	Logger.Print(ctx, Constants.SymbolOK+" All endpoints report the same cluster ID"+Constants.TwoNewLines)
+	result.OK = true
+	result.Detail = "cluster IDs: " + strings.Join(reported, ", ")
+	return result
+}
+
+// StatefulSetUpdates fails when a rolling update of an ostore StatefulSet appears stalled: its pods are
+// split between the current and update revisions and fewer than all replicas have been updated for
+// longer than grace since the update revision was created. Such a StatefulSet leaves some pods on the
+// old revision indefinitely.
+func StatefulSetUpdates(ctx context.Context, clientset *kubernetes.Clientset, namespace string, grace time.Duration) CheckResult {
+	result := CheckResult{Name: "statefulset-updates"}
+	statefulSets, err := clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to list StatefulSets in namespace %s: %s", namespace, err)
+		return result
+	}
+
+	stalled, updating := []string{}, []string{}
+	for _, statefulSet := range statefulSets.Items {
+		status := statefulSet.Status
+		replicas := int32(1)
+		if statefulSet.Spec.Replicas != nil {
+			replicas = *statefulSet.Spec.Replicas
+		}
+		if status.UpdateRevision == "" || status.CurrentRevision == status.UpdateRevision || status.UpdatedReplicas >= replicas {
+			Logger.Debugf(ctx, Constants.SymbolOK+" StatefulSet: %s | Revision: %s | Updated: %d/%d", statefulSet.Name, status.CurrentRevision, status.UpdatedReplicas, replicas)
+			continue
+		}
+
+		gap := fmt.Sprintf("StatefulSet '%s' has %d/%d replicas on update revision %s (current revision %s)",
+			statefulSet.Name, status.UpdatedReplicas, replicas, status.UpdateRevision, status.CurrentRevision)
+		// The update revision is created when the rollout starts, so its age is how long the update has run.
+		revision, err := clientset.AppsV1().ControllerRevisions(namespace).Get(ctx, status.UpdateRevision, metav1.GetOptions{})
+		if err != nil {
+			Logger.Printf(ctx, Constants.SymbolWarn+" Unable to get controller revision %s, cannot tell how long the update has run: %v", status.UpdateRevision, err)
+			updating = append(updating, gap)
+			continue
+		}
+		age := time.Since(revision.CreationTimestamp.Time).Round(time.Second)
+		if age > grace {
+			stalled = append(stalled, fmt.Sprintf("%s for %s", gap, age))
+			continue
+		}
+		Logger.Printf(ctx, Constants.SymbolWarn+" %s, updating for %s", gap, age)
+		updating = append(updating, gap)
+	}
+
+	if len(stalled) > 0 {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" StatefulSet updates stalled longer than %s: %s", grace, strings.Join(stalled, "; "))
+		return result
+	}
+	result.OK = true
+	if len(updating) > 0 {
+		Logger.Print(ctx, Constants.TwoNewLines)
+		result.Status = StatusWarn
+		result.Detail = "StatefulSet updates in progress: " + strings.Join(updating, "; ")
+		return result
+	}
+	Logger.Print(ctx, Constants.SymbolOK+" No ostore StatefulSet has a pending rolling update"+Constants.TwoNewLines)
+	return result
+}
+
+// nodeRefFields are the keys a diskset or its member disks may use to reference a node, and
+// nodeIdentityFields the keys of a /node entry those references may match.
+var (
+	nodeRefFields      = []string{"node_id", "node_name", "hostname", "node", "node_ip"}
+	nodeIdentityFields = []string{"id", "node_id", "hostname", "ip", "node_ip"}
+)
+
+// disksetNodeRefs returns the node references of a diskset, taken from the diskset itself and from its
+// member disks.
+func disksetNodeRefs(diskset map[string]interface{}) []string {
+	refs := []string{}
+	add := func(entry map[string]interface{}) {
+		for _, field := range nodeRefFields {
+			if value, found := entry[field]; found && value != nil {
+				if ref := Utils.ID(value); ref != "" && !slices.Contains(refs, ref) {
+					refs = append(refs, ref)
+				}
+				return
+			}
+		}
+	}
+	add(diskset)
+	for _, field := range disksetMemberFields {
+		members, ok := diskset[field].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, item := range members {
+			if member, ok := item.(map[string]interface{}); ok {
+				add(member)
+			}
+		}
+		break
+	}
+	return refs
+}
+
+// DisksetNodeMembership cross-references the nodes disksets reference against /node and fails when a
+// diskset references a node that is missing or not ACTIVE, which is stale diskset metadata typically
+// left behind by a node replacement. The check is skipped when disksets don't reference nodes.
+func DisksetNodeMembership(ctx context.Context, auth Utils.Authenticator, serviceIP string) CheckResult {
+	result := CheckResult{Name: "diskset-nodes"}
+	nodesJSON, err := fetchJSON(ctx, auth, Utils.EndpointURL(serviceIP, "node"))
+	if err != nil {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to get nodes: %s", err)
+		return result
+	}
+	disksetsJSON, err := fetchJSON(ctx, auth, Utils.EndpointURL(serviceIP, "diskset"))
+	if err != nil {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to get disksets: %s", err)
+		return result
+	}
+
+	nodeList, ok := nodesJSON.([]interface{})
+	if !ok {
+		result.Detail = fmt.Sprintf("unexpected JSON structure: expected an array of nodes, but got %T", nodesJSON)
+		return result
+	}
+	disksetMap, ok := disksetsJSON.(map[string]interface{})
+	if !ok {
+		result.Detail = "unexpected JSON structure: expected an object at the top level of the diskset response"
+		return result
+	}
+	disksets, _ := disksetMap[Utils.Field("diskset", "disksets")].([]interface{})
+
+	// states maps every identifier of a node to its status.
+	states := map[string]string{}
+	for _, item := range nodeList {
+		node, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		status, _ := node[Utils.Field("node", "status_str")].(string)
+		for _, field := range append([]string{Utils.Field("node", "name")}, nodeIdentityFields...) {
+			if value, found := node[field]; found && value != nil && Utils.ID(value) != "" {
+				states[Utils.ID(value)] = status
+			}
+		}
+	}
+
+	referenced := false
+	offending := []string{}
+	for _, item := range disksets {
+		diskset, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		disksetID := Utils.ID(diskset[Utils.Field("diskset", "id")])
+		for _, ref := range disksetNodeRefs(diskset) {
+			referenced = true
+			status, found := states[ref]
+			switch {
+			case !found:
+				offending = append(offending, fmt.Sprintf("diskset %s references missing node '%s'", disksetID, ref))
+			case status != "ACTIVE":
+				offending = append(offending, fmt.Sprintf("diskset %s references node '%s' in state %s", disksetID, ref, status))
+			default:
+				Logger.Debugf(ctx, Constants.SymbolOK+" Diskset ID: %s, Node: %s is ACTIVE", disksetID, ref)
+			}
+		}
+	}
+
+	if !referenced {
+		Logger.Print(ctx, Constants.SymbolInfo+" The diskset response does not reference nodes, skipping diskset node membership check"+Constants.TwoNewLines)
+		result.OK = true
+		result.Status = StatusSkip
+		result.Detail = "diskset node references not exposed by the gateway"
+		return result
+	}
+	if len(offending) > 0 {
+		result.Detail = Constants.SymbolFail + " Stale diskset node membership: " + strings.Join(offending, "; ")
+		return result
+	}
+
+	Logger.Print(ctx, Constants.SymbolOK+" Every node referenced by a diskset is ACTIVE"+Constants.TwoNewLines)
+	result.OK = true
+	result.Detail = "every node referenced by a diskset is ACTIVE"
+	return result
+}
+
+// listenerPortFields are the keys the listener config may use for the port of each listener the tool
+// connects to, keyed by the port the tool expects it on.
+var listenerPortFields = []struct {
+	listener string
+	expected func() string
+	fields   []string
+}{
+	{"gateway", Utils.GatewayPort, []string{"gateway_port", "s3_port", "data_port", "http_port"}},
+	{"admin", Utils.AdminPort, []string{"admin_port", "management_port", "mgmt_port"}},
+}
+
+// ListenerPorts compares the ports the gateway reports listening on with the gateway and admin ports the
+// tool connects to, so a gateway reconfigured to non-default ports is named as the cause instead of
+// surfacing as unreachable endpoints. Gateways that don't expose their listener config skip the check.
+func ListenerPorts(ctx context.Context, auth Utils.Authenticator, serviceIP string) CheckResult {
+	result := CheckResult{Name: "listener-ports", OK: true}
+	parsedJSON, err := fetchJSON(ctx, auth, Utils.EndpointURL(serviceIP, "listeners"))
+	response, ok := parsedJSON.(map[string]interface{})
+	if err != nil || !ok {
+		Logger.Print(ctx, Constants.SymbolInfo+" The gateway does not expose its listener config, skipping listener port check"+Constants.TwoNewLines)
+		result.Status = StatusSkip
+		result.Detail = "listener config not exposed"
+		return result
+	}
+
+	ports := map[string]string{}
+	mismatches := []string{}
+	for _, listener := range listenerPortFields {
+		for _, field := range listener.fields {
+			port, found := Utils.Int64(response[Utils.Field("listeners", field)])
+			if !found {
+				continue
+			}
+			actual := strconv.FormatInt(port, 10)
+			ports[listener.listener] = actual
+			expected := listener.expected()
+			Logger.Debugf(ctx, "Listener: %s | Port: %s | Expected: %s", listener.listener, actual, expected)
+			if actual != expected {
+				mismatches = append(mismatches, fmt.Sprintf("%s listener is on port %s, expected %s", listener.listener, actual, expected))
+			}
+			break
+		}
+	}
+	result.Data = map[string]interface{}{"ports": ports}
+
+	if len(ports) == 0 {
+		Logger.Print(ctx, Constants.SymbolInfo+" The listener config does not report any listener ports, skipping listener port check"+Constants.TwoNewLines)
+		result.Status = StatusSkip
+		result.Detail = "listener ports not reported"
+		return result
+	}
+	if len(mismatches) > 0 {
+		for _, mismatch := range mismatches {
+			Logger.Printf(ctx, Constants.SymbolFail+" %s", mismatch)
+		}
+		Logger.Print(ctx, Constants.TwoNewLines)
+		result.OK = false
+		result.Detail = Constants.SymbolFail + " gateway listener ports differ from the configured ports: " + strings.Join(mismatches, "; ")
+		return result
+	}
+
+	Logger.Print(ctx, Constants.SymbolOK+" The gateway listens on the configured ports"+Constants.TwoNewLines)
+	result.Detail = "gateway listens on the configured ports"
+	return result
+}
+
+// disksetCapacityFields are the keys the /diskset response may use for a diskset's raw capacity in bytes.
+var disksetCapacityFields = []string{"raw_capacity", "capacity", "total_capacity", "size", "total_bytes"}
+
+// storageEfficiency returns the fraction of raw capacity a scheme leaves usable: k/(k+m) for an erasure
+// coding scheme such as "4+2", or 1/n for a replication scheme such as "3x".
+func storageEfficiency(scheme string) (float64, bool) {
+	if match := ecSchemePattern.FindStringSubmatch(scheme); match != nil {
+		data, dataErr := strconv.Atoi(match[1])
+		parity, parityErr := strconv.Atoi(match[2])
+		if dataErr != nil || parityErr != nil || data < 1 {
+			return 0, false
+		}
+		return float64(data) / float64(data+parity), true
+	}
+	tolerated, ok := failuresTolerated(scheme)
+	if !ok {
+		return 0, false
+	}
+	return 1 / float64(tolerated+1), true
+}
+
+// UsableCapacity totals the raw capacity of every diskset and estimates the capacity left usable after
+// the overhead of each diskset's redundancy scheme. It is informational, except that it warns when the
+// usable capacity is below minUsable bytes (0 disables). Disksets without a scheme count towards the raw
+// total only, and gateways that don't expose diskset capacity skip the check.
+func UsableCapacity(ctx context.Context, auth Utils.Authenticator, serviceIP string, minUsable int64) CheckResult {
+	result := CheckResult{Name: "usable-capacity", OK: true}
+	parsedJSON, err := fetchJSON(ctx, auth, Utils.EndpointURL(serviceIP, "diskset"))
+	if err != nil {
+		result.OK = false
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to get disksets: %s", err)
+		return result
+	}
+	parsedJSONMap, ok := parsedJSON.(map[string]interface{})
+	if !ok {
+		result.OK = false
+		result.Detail = "unexpected JSON structure: expected an object at the top level"
+		return result
+	}
+	disksets, _ := parsedJSONMap[Utils.Field("diskset", "disksets")].([]interface{})
+
+	var raw, usable, estimatedRaw int64
+	unknown := []string{}
+	for _, item := range disksets {
+		diskset, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var capacity int64
+		found := false
+		for _, field := range disksetCapacityFields {
+			if capacity, found = Utils.Int64(diskset[Utils.Field("diskset", field)]); found {
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+		disksetID := Utils.ID(diskset[Utils.Field("diskset", "id")])
+		raw += capacity
+
+		scheme := ""
+		for _, field := range ecSchemeFields {
+			if value, ok := diskset[field].(string); ok && value != "" {
+				scheme = value
+				break
+			}
+		}
+		efficiency, ok := storageEfficiency(scheme)
+		if !ok {
+			Logger.Debugf(ctx, "Diskset ID: %v, Raw capacity: %s, Redundancy scheme: unknown", disksetID, Utils.Bytes(capacity))
+			unknown = append(unknown, disksetID)
+			continue
+		}
+		Logger.Debugf(ctx, "Diskset ID: %v, Raw capacity: %s, Redundancy scheme: %s, Usable: %s", disksetID, Utils.Bytes(capacity), scheme, Utils.Bytes(int64(float64(capacity)*efficiency)))
+		estimatedRaw += capacity
+		usable += int64(float64(capacity) * efficiency)
+	}
+
+	if raw == 0 {
+		Logger.Print(ctx, Constants.SymbolInfo+" The diskset response does not expose capacity, skipping usable capacity check"+Constants.TwoNewLines)
+		result.Status = StatusSkip
+		result.Detail = "diskset capacity not exposed by the gateway"
+		return result
+	}
+
+	result.Data = map[string]interface{}{"raw_bytes": raw, "usable_bytes": usable}
+	result.Detail = fmt.Sprintf("raw %s, usable %s", Utils.Bytes(raw), Utils.Bytes(usable))
+	if estimatedRaw > 0 {
+		overhead := 100 * float64(estimatedRaw-usable) / float64(estimatedRaw)
+		result.Data["overhead_pct"] = overhead
+		result.Detail += fmt.Sprintf(", %.1f%% durability overhead", overhead)
+	}
+	if len(unknown) > 0 {
+		result.Detail += fmt.Sprintf("; disksets without a redundancy scheme count towards raw only: %s", strings.Join(unknown, ", "))
+	}
+	Logger.Print(ctx, " Capacity: "+result.Detail)
+
+	if minUsable > 0 && usable < minUsable {
+		Logger.Printf(ctx, Constants.SymbolWarn+" Usable capacity %s is below the expected %s%s", Utils.Bytes(usable), Utils.Bytes(minUsable), Constants.TwoNewLines)
+		result.Status = StatusWarn
+		result.Detail = fmt.Sprintf("usable capacity below the expected %s: %s", Utils.Bytes(minUsable), result.Detail)
+		return result
+	}
+	Logger.Print(ctx, Constants.TwoNewLines)
+	return result
+}
+
+// disksetUsedFields are the keys the /diskset response may use for the space in use, on each diskset
+// or, next to the list, for the whole cluster.
+var disksetUsedFields = []string{"used_capacity", "used", "used_bytes", "usage"}
+
+// CapacityStatus reports how full the cluster is as used over total capacity, warning above warnPct and
+// failing above failPct percent. The cluster totals are used when the /diskset response carries them
+// next to the list, otherwise the disksets' own figures are summed. Gateways that expose neither skip
+// the check.
+func CapacityStatus(ctx context.Context, auth Utils.Authenticator, serviceIP string, warnPct, failPct float64) CheckResult {
+	result := CheckResult{Name: "capacity"}
+	parsedJSON, err := fetchJSON(ctx, auth, Utils.EndpointURL(serviceIP, "diskset"))
+	if err != nil {
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to get disksets: %s", err)
+		result.Err = err
+		return result
+	}
+	parsedJSONMap, ok := parsedJSON.(map[string]interface{})
+	if !ok {
+		result.Detail = "unexpected JSON structure: expected an object at the top level"
+		return result
+	}
+
+	total, totalFound := firstInt64(parsedJSONMap, disksetCapacityFields)
+	used, usedFound := firstInt64(parsedJSONMap, disksetUsedFields)
+	if !totalFound || !usedFound {
+		total, used = 0, 0
+		disksets, _ := parsedJSONMap[Utils.Field("diskset", "disksets")].([]interface{})
+		for _, item := range disksets {
+			diskset, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			capacity, capacityFound := firstInt64(diskset, disksetCapacityFields)
+			inUse, inUseFound := firstInt64(diskset, disksetUsedFields)
+			if !capacityFound || !inUseFound {
+				continue
+			}
+			Logger.Debugf(ctx, "Diskset ID: %v, Used: %s of %s", Utils.ID(diskset[Utils.Field("diskset", "id")]), Utils.Bytes(inUse), Utils.Bytes(capacity))
+			total += capacity
+			used += inUse
+		}
+	}
+
+	if total <= 0 {
+		Logger.Print(ctx, Constants.SymbolInfo+" The diskset response does not expose capacity usage, skipping capacity check"+Constants.TwoNewLines)
+		result.OK = true
+		result.Status = StatusSkip
+		result.Detail = "capacity usage not exposed by the gateway"
+		return result
+	}
+
+	pct := 100 * float64(used) / float64(total)
+	result.Data = map[string]interface{}{"used_bytes": used, "total_bytes": total, "used_pct": pct}
+	usage := fmt.Sprintf("%s used of %s (%.1f%%)", Utils.Bytes(used), Utils.Bytes(total), pct)
+	Logger.Print(ctx, " Capacity: "+usage)
+
+	switch {
+	case pct > failPct:
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" cluster is nearly full: %s, above the %.0f%% failure threshold", usage, failPct)
+		return result
+	case pct > warnPct:
+		Logger.Printf(ctx, Constants.SymbolWarn+" Capacity usage is above %.0f%%%s", warnPct, Constants.TwoNewLines)
+		result.OK = true
+		result.Status = StatusWarn
+		result.Detail = fmt.Sprintf("%s, above the %.0f%% warning threshold", usage, warnPct)
+		return result
+	}
+
+	Logger.Printf(ctx, Constants.SymbolOK+" Capacity usage is below %.0f%%%s", warnPct, Constants.TwoNewLines)
+	result.OK = true
+	result.Detail = usage
+	return result
+}
+
+// firstInt64 returns the first of the diskset fields present in object as an integer.
+func firstInt64(object map[string]interface{}, fields []string) (int64, bool) {
+	for _, field := range fields {
+		if value, found := Utils.Int64(object[Utils.Field("diskset", field)]); found {
+			return value, true
+		}
+	}
+	return 0, false
+}
+
+// replicationBandwidthFields and replicationScheduleFields are the keys the replication config may use
+// for a replication's bandwidth cap and its schedule, at the top level or per replicated cluster.
+var (
+	replicationBandwidthFields = []string{"BandwidthLimit", "bandwidth_limit", "Bandwidth", "bandwidth", "MaxBandwidth"}
+	replicationScheduleFields  = []string{"Schedule", "schedule", "Interval", "interval", "replication_interval"}
+)
+
+// neverSchedules are schedule values that mean the replication never runs.
+var neverSchedules = []string{"NEVER", "DISABLED", "NONE", "PAUSED", "OFF"}
+
+// daysInMonth is the most days each month can have, indexed from 1.
+var daysInMonth = [13]int{0, 31, 29, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}
+
+// scheduleNeverRuns reports whether a replication schedule can never fire: a "never"-like keyword, a
+// non-positive interval, or a cron expression pinned to a day its month doesn't have such as "0 0 31 2 *".
+func scheduleNeverRuns(value interface{}) bool {
+	if interval, ok := Utils.Int64(value); ok {
+		return interval <= 0
+	}
+	schedule, ok := value.(string)
+	if !ok {
+		return false
+	}
+	schedule = strings.TrimSpace(schedule)
+	if slices.Contains(neverSchedules, strings.ToUpper(schedule)) {
+		return true
+	}
+	fields := strings.Fields(schedule)
+	if len(fields) != 5 {
+		return false
+	}
+	day, dayErr := strconv.Atoi(fields[2])
+	month, monthErr := strconv.Atoi(fields[3])
+	return dayErr == nil && monthErr == nil && month >= 1 && month <= 12 && day > daysInMonth[month]
+}
+
+// ReplicationSchedule inspects the bandwidth caps and schedules in the replication config and warns when
+// a bandwidth cap is 0 or a schedule can never run, since such a replication reports ONLINE while it is
+// effectively paused. Configs without replication, or without either setting, skip the check.
+func ReplicationSchedule(ctx context.Context, auth Utils.Authenticator, serviceIP string) CheckResult {
+	result := CheckResult{Name: "replication-schedule", OK: true}
+	parsedJSON, err := fetchJSON(ctx, auth, Utils.EndpointURL(serviceIP, "replication"))
+	if err != nil {
+		result.OK = false
+		result.Detail = fmt.Sprintf(Constants.SymbolFail+" failed to get replication config: %s", err)
+		return result
+	}
+	parsedJSONMap, ok := parsedJSON.(map[string]interface{})
+	if !ok {
+		result.OK = false
+		result.Detail = "unexpected JSON structure: expected an object at the top level"
+		return result
+	}
+
+	// The top-level settings apply to every replicated cluster unless it overrides them.
+	configs := map[string]map[string]interface{}{"replication": parsedJSONMap}
+	order := []string{"replication"}
+	replicatedClusters, _ := parsedJSONMap[Utils.Field("replication", "ReplicatedClusters")].([]interface{})
+	for i, item := range replicatedClusters {
+		if cluster, ok := item.(map[string]interface{}); ok {
+			name := fmt.Sprintf("replicated cluster %d", i+1)
+			if id, found := cluster[Utils.Field("replication", "Name")].(string); found && id != "" {
+				name = "replicated cluster '" + id + "'"
+			}
+			configs[name] = cluster
+			order = append(order, name)
+		}
+	}
+
+	exposed := false
+	findings := []string{}
+	for _, name := range order {
+		config := configs[name]
+		for _, field := range replicationBandwidthFields {
+			value, found := config[Utils.Field("replication", field)]
+			if !found {
+				continue
+			}
+			exposed = true
+			Logger.Debugf(ctx, "%s: %s = %v", name, field, value)
+			if bandwidth, ok := Utils.Int64(value); ok && bandwidth == 0 {
+				findings = append(findings, fmt.Sprintf("%s has %s set to 0", name, field))
+			}
+			break
+		}
+		for _, field := range replicationScheduleFields {
+			value, found := config[Utils.Field("replication", field)]
+			if !found {
+				continue
+			}
+			exposed = true
+			Logger.Debugf(ctx, "%s: %s = %v", name, field, value)
+			if scheduleNeverRuns(value) {
+				findings = append(findings, fmt.Sprintf("%s has %s '%v', which never runs", name, field, value))
+			}
+			break
+		}
+	}
+
+	if !exposed {
+		Logger.Print(ctx, Constants.SymbolInfo+" The replication config has no bandwidth cap or schedule, skipping replication schedule check"+Constants.TwoNewLines)
+		result.Status = StatusSkip
+		result.Detail = "no bandwidth cap or schedule configured"
+		return result
+	}
+	if len(findings) > 0 {
+		for _, finding := range findings {
+			Logger.Printf(ctx, Constants.SymbolWarn+" %s", finding)
+		}
+		Logger.Print(ctx, Constants.TwoNewLines)
+		result.Status = StatusWarn
+		result.Detail = "replication is effectively paused: " + strings.Join(findings, "; ")
+		return result
+	}
+
+	Logger.Print(ctx, Constants.SymbolOK+" Replication bandwidth caps and schedules allow replication to run"+Constants.TwoNewLines)
+	result.Detail = "replication bandwidth caps and schedules allow replication to run"
+	return result
+}
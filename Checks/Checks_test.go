@@ -0,0 +1,220 @@
+package checks
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	Utils "Detective/Utils"
+
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// gatewayServer starts a TLS server answering every request with body and points the gateway ports at
+// it, returning the address to pass as serviceIP.
+func gatewayServer(t *testing.T, body string) string {
+	t.Helper()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, _ := strconv.Atoi(port)
+	if err := Utils.SetPorts(p, p); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { Utils.SetPorts(9001, 9000) })
+	return host
+}
+
+func TestReplicationStatusRoles(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		ok     bool
+		detail string
+	}{
+		{
+			name:   "primary-only",
+			body:   `{"Role": "PRIMARY", "ReplicatedClusters": [{"Name": "dr", "Health": "ONLINE", "Role": "REPLICA"}]}`,
+			ok:     true,
+			detail: "local PRIMARY",
+		},
+		{
+			name:   "replica-only",
+			body:   `{"Role": "SECONDARY", "ReplicatedClusters": [{"Name": "main", "Health": "ONLINE", "Role": "SOURCE"}]}`,
+			ok:     true,
+			detail: "local REPLICA",
+		},
+		{
+			name:   "both primary",
+			body:   `{"Role": "PRIMARY", "ReplicatedClusters": [{"Name": "dr", "Health": "ONLINE", "Role": "MASTER"}]}`,
+			detail: "both the local cluster and the peer are PRIMARY",
+		},
+		{
+			name:   "both replica",
+			body:   `{"Role": "REPLICA", "ReplicatedClusters": [{"Name": "dr", "Health": "ONLINE", "Role": "TARGET"}]}`,
+			detail: "both the local cluster and the peer are REPLICA",
+		},
+		{
+			name:   "undefined local role",
+			body:   `{"ReplicatedClusters": [{"Name": "dr", "Health": "ONLINE", "Role": "REPLICA"}]}`,
+			detail: "role is undefined",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceIP := gatewayServer(t, tt.body)
+
+			result := ReplicationStatus(context.Background(), Utils.StaticTokenAuthenticator{Value: "token"}, serviceIP)
+
+			if result.OK != tt.ok {
+				t.Errorf("OK = %v, want %v (detail: %s)", result.OK, tt.ok, result.Detail)
+			}
+			if !strings.Contains(result.Detail, tt.detail) {
+				t.Errorf("detail %q does not mention %q", result.Detail, tt.detail)
+			}
+		})
+	}
+}
+
+func TestAllPodsAreRunningCreateContainerConfigError(t *testing.T) {
+	clientset := fake.NewClientset(&v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "ostore-gateway-0", Namespace: "ostore"},
+		Status: v1.PodStatus{
+			Phase: v1.PodPending,
+			ContainerStatuses: []v1.ContainerStatus{{
+				Name: "gateway",
+				State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{
+					Reason:  "CreateContainerConfigError",
+					Message: `secret "ostore-credentials" not found`,
+				}},
+			}},
+		},
+	})
+
+	result := AllPodsAreRunning(context.Background(), clientset, "ostore", nil)
+
+	if result.OK {
+		t.Fatal("expected the check to fail")
+	}
+	for _, want := range []string{"ostore-gateway-0", "CreateContainerConfigError", containerCreateFailures["CreateContainerConfigError"], "ostore-credentials"} {
+		if !strings.Contains(result.Detail, want) {
+			t.Errorf("detail %q does not mention %q", result.Detail, want)
+		}
+	}
+}
+
+func TestAllPodsAreRunningUnknownPhase(t *testing.T) {
+	clientset := fake.NewClientset(&v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "ostore-gateway-0", Namespace: "ostore"},
+		Spec:       v1.PodSpec{NodeName: "worker-3"},
+		Status:     v1.PodStatus{Phase: v1.PodUnknown},
+	})
+
+	result := AllPodsAreRunning(context.Background(), clientset, "ostore", nil)
+
+	if result.OK {
+		t.Fatal("expected the check to fail")
+	}
+	for _, want := range []string{"ostore-gateway-0", "'Unknown' phase", "worker-3", "unreachable"} {
+		if !strings.Contains(result.Detail, want) {
+			t.Errorf("detail %q does not mention %q", result.Detail, want)
+		}
+	}
+}
+
+func TestDisksetStatusKeepsRebuildRecordsOnFailure(t *testing.T) {
+	serviceIP := gatewayServer(t, `{"disksets": [
+		{"id": "ds-1", "health_str": "HEALTHY", "status_str": "REBUILDING"},
+		{"id": "ds-2", "health_str": "DEGRADED", "status_str": "REBUILDING"}
+	]}`)
+	since := time.Now().Add(-time.Hour)
+	state := &Utils.State{Rebuilding: map[string]Utils.RebuildRecord{
+		"ds-1": {Since: since, Runs: 2},
+		"ds-2": {Since: since, Runs: 3},
+	}}
+
+	result := DisksetStatus(context.Background(), Utils.StaticTokenAuthenticator{Value: "token"}, serviceIP, state, RebuildLimits{})
+
+	if result.OK {
+		t.Fatalf("OK = true, want a failure for the unhealthy diskset")
+	}
+	for id, runs := range map[string]int{"ds-1": 2, "ds-2": 3} {
+		if record := state.Rebuilding[id]; record.Runs != runs || !record.Since.Equal(since) {
+			t.Errorf("state.Rebuilding[%q] = %+v, want the previous record with %d runs", id, record, runs)
+		}
+	}
+}
+
+func TestAllPodsAreRunningOnlySkipsDrainingGatewayPods(t *testing.T) {
+	terminating := func(name string) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "ostore",
+			DeletionTimestamp: &metav1.Time{Time: time.Now().Add(time.Minute)},
+		}}
+	}
+	slice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ostore-gateway-server-abc",
+			Namespace: "ostore",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: "ostore-gateway-server"},
+		},
+		Endpoints: []discoveryv1.Endpoint{{
+			Addresses: []string{"10.0.0.1"},
+			TargetRef: &v1.ObjectReference{Kind: "Pod", Name: "ostore-gateway-0"},
+		}},
+	}
+
+	tests := []struct {
+		name           string
+		pod            string
+		gatewayService string
+		problem        string
+	}{
+		{"gateway pod draining", "ostore-gateway-0", "ostore-gateway-server", ""},
+		{"other pod terminating", "ostore-cm-0", "ostore-gateway-server", "pod 'ostore-cm-0' is terminating"},
+		{"no gateway service", "ostore-gateway-0", "", "pod 'ostore-gateway-0' is terminating"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewClientset(terminating(tt.pod), slice)
+
+			problem, _, _ := allPodsAreRunning(context.Background(), clientset, "ostore", tt.gatewayService, nil)
+
+			if tt.problem == "" && problem != "" || !strings.Contains(problem, tt.problem) {
+				t.Errorf("problem = %q, want %q", problem, tt.problem)
+			}
+		})
+	}
+}
+
+func TestSupportedKubernetesVersions(t *testing.T) {
+	tests := []struct {
+		name, version string
+		found         bool
+	}{
+		{"ostore", "1.5.0", true},
+		{"ostore", "1.5.3", true},
+		{"ostore", "1.6.0", false},
+		{"other", "1.5.0", false},
+	}
+	for _, tt := range tests {
+		if _, found := supportedKubernetesVersions(tt.name, tt.version); found != tt.found {
+			t.Errorf("supportedKubernetesVersions(%q, %q) found = %v, want %v", tt.name, tt.version, found, tt.found)
+		}
+	}
+}
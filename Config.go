@@ -0,0 +1,459 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	Constants "Detective/Constants"
+	Utils "Detective/Utils"
+
+	"github.com/Masterminds/semver/v3"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Config holds the tunables for a single diagnostic run, populated from the
+// command line.
+type Config struct {
+	// AllowedRegistries lists the image registries ostore pods may pull from.
+	// An empty list disables the registry check.
+	AllowedRegistries []string
+	// ProbeIngress dials every LoadBalancer ingress entry of the gateway
+	// service to find out which ones are reachable.
+	ProbeIngress bool
+	// StateFile persists observations between runs so checks can detect
+	// changes over time. Empty disables the run-over-run checks.
+	StateFile string
+	// MaxObjectDropPct is the largest drop in bucket/object count, in percent,
+	// tolerated between two runs.
+	MaxObjectDropPct float64
+	// RedactEndpoints replaces IPs and hostnames in all output with stable
+	// placeholders so reports can be shared externally.
+	RedactEndpoints bool
+	// Parallelism caps how many checks run at once; 1 runs them serially.
+	Parallelism int
+	// ExpectedECScheme is the minimum redundancy every diskset must provide,
+	// e.g. "4+2". Empty only reports the schemes.
+	ExpectedECScheme string
+	// OIDCTokenFile is a file holding a short-lived token used instead of
+	// logging in with a username and password. It is re-read on every 401.
+	OIDCTokenFile string
+	// Token is a gateway token issued out of band, sent as x-rakuten-token,
+	// and BearerToken one sent as "Authorization: Bearer". Either replaces
+	// logging in.
+	Token       string
+	BearerToken string
+	// RequiredNetworkPolicies lists the NetworkPolicies that must exist in
+	// the ostore namespace. An empty list disables the check.
+	RequiredNetworkPolicies []string
+	// Watch re-runs the diagnostic every WatchInterval until interrupted.
+	Watch         bool
+	WatchInterval time.Duration
+	// Serve re-runs the diagnostic every WatchInterval like Watch, and
+	// publishes the last run as Prometheus metrics at /metrics on Listen.
+	Serve  bool
+	Listen string
+	// BreakerThreshold is the number of consecutive failures after which a
+	// check is skipped in watch or serve mode for BreakerCooldown.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+	// FieldMap is a JSON file remapping the response keys the checks read,
+	// keyed by "endpoint.field", for gateways that renamed them.
+	FieldMap string
+	// EndpointOverrides relocates gateway API endpoints, keyed by endpoint
+	// name, e.g. cluster_health -> /v2/cluster_health.
+	EndpointOverrides map[string]string
+	// SecurityChecks enables the security posture checks, which probe the
+	// gateway with deliberately unauthenticated requests.
+	SecurityChecks bool
+	// AllContexts runs the diagnostic against every context in the
+	// kubeconfig and prints a per-cluster rollup.
+	AllContexts bool
+	// DiskStatusesOK and DiskStatusesTransient classify disk status strings;
+	// transient statuses are reported as warnings instead of failures.
+	DiskStatusesOK        []string
+	DiskStatusesTransient []string
+	// LoginPath is the gateway path that exchanges credentials for a token.
+	LoginPath string
+	// FailOnZombieNodes fails the node check for nodes stuck in a
+	// decommission or removal state instead of only warning.
+	FailOnZombieNodes bool
+	// MaxRebuildRuns and MaxRebuildDuration bound how long a diskset may
+	// stay REBUILDING across runs recorded in StateFile. Zero disables a limit.
+	MaxRebuildRuns     int
+	MaxRebuildDuration time.Duration
+	// ExpectedLabels are the labels every ostore Deployment and StatefulSet
+	// must carry; an empty value only requires the label to be present.
+	ExpectedLabels map[string]string
+	// KubeconfigFromStdin reads the kubeconfig from stdin instead of
+	// ~/.kube/config, so it never has to be written to disk.
+	KubeconfigFromStdin bool
+	// InCluster uses the pod's service account instead of a kubeconfig. It
+	// is detected automatically when ~/.kube/config does not exist.
+	InCluster bool
+	// RunID tags the run in every report; a timestamp-based ID is generated
+	// per run when empty. Title replaces the default banner.
+	RunID string
+	Title string
+	// VerifyTLS verifies the gateway's certificate and checks that its SANs
+	// cover the endpoint being used.
+	VerifyTLS bool
+	// DumpValues prints the release's user-supplied Helm values, with
+	// credentials redacted.
+	DumpValues bool
+	// FailOnLatestTag fails the image tag check for containers running
+	// :latest or an untagged image; otherwise they are only warned about.
+	FailOnLatestTag bool
+	// Outputs lists the --output destinations as "format" for stdout or
+	// "format:path" for a file, e.g. "human" and "junit:results.xml".
+	// A bare "prometheus-textfile" writes into TextfileDir.
+	Outputs     []string
+	TextfileDir string
+	// ReportFile archives the complete report of every run in ReportFormat,
+	// replacing the file or, with ReportAppend, adding to its end.
+	ReportFile   string
+	ReportFormat string
+	ReportAppend bool
+	// MinGatewayReplicas is the fewest ready gateway endpoints the service
+	// may have.
+	MinGatewayReplicas int
+	// MaxBackupAge is the oldest the last successful backup may be. Zero
+	// only requires a backup schedule.
+	MaxBackupAge time.Duration
+	// TraceHTTP logs per-request DNS, connect, TLS and first-byte timings
+	// at DEBUG level.
+	TraceHTTP bool
+	// Verbose logs DEBUG messages, such as every node and disk a check
+	// looked at.
+	Verbose bool
+	// Quiet logs only warnings and errors and skips the progress headers,
+	// leaving the final verdict.
+	Quiet bool
+	// RetryAttempts is how many times in total a gateway request is tried on
+	// connection errors and 5xx responses, waiting RetryDelay before the
+	// first retry and doubling it for every further one.
+	RetryAttempts int
+	RetryDelay    time.Duration
+	// ControlPlaneNamespace is where the control-plane and system pods run.
+	ControlPlaneNamespace string
+	// RecentRestartWindow warns when the cluster restarted more recently
+	// than this. Zero disables the warning.
+	RecentRestartWindow time.Duration
+	// NoK8s skips Helm discovery and every check that needs the Kubernetes
+	// API, probing only the gateway API at ServiceIP.
+	NoK8s bool
+	// ServiceIP is the gateway address to probe instead of the one
+	// discovered from the gateway service. Required with NoK8s.
+	ServiceIP string
+	// MaxDisksetImbalancePct is how many percent fewer disksets the least
+	// loaded node may hold than the most loaded one before warning.
+	MaxDisksetImbalancePct float64
+	// AllowDisksetDecrease only warns when the diskset count dropped since
+	// the previous run, for planned removals.
+	AllowDisksetDecrease bool
+	// LoadCheck fires LoadCheckRequests concurrent requests at the gateway
+	// and fails when more than LoadCheckMaxErrorPct percent of them fail.
+	// Off by default since it adds load.
+	LoadCheck            bool
+	LoadCheckRequests    int
+	LoadCheckMaxErrorPct float64
+	// TimeSyncDaemonSet names the time-sync DaemonSet as "namespace/name",
+	// or "name" in ControlPlaneNamespace. Empty disables the check.
+	TimeSyncDaemonSet string
+	// LatestChartVersions are the chart versions known to be available; the
+	// newest is compared against the deployed one. Empty disables the check.
+	LatestChartVersions []string
+	// FailThreshold is how many failing checks are tolerated before the
+	// cluster is reported unhealthy. CriticalBypassesThreshold makes any
+	// critical failure unhealthy regardless.
+	FailThreshold             int
+	CriticalBypassesThreshold bool
+	// StatefulSetUpdateGrace is how long a StatefulSet rolling update may
+	// leave replicas on the old revision before it is reported as stalled.
+	StatefulSetUpdateGrace time.Duration
+	// UserAgent is sent with every gateway request.
+	UserAgent string
+	// ASCII replaces the emoji status symbols with [OK], [FAIL] and [WARN]
+	// for terminals and log collectors that cannot render them.
+	ASCII bool
+	// MinReadyDuration is how long every required pod must have been Ready
+	// before it counts as stable rather than stabilizing. 0 disables.
+	MinReadyDuration time.Duration
+	// ExpectedUsableCapacity is the usable capacity, in bytes, below which
+	// the cluster is reported as short on space. 0 disables.
+	ExpectedUsableCapacity int64
+	// IDPTypes lists the identity provider types the idp check queries.
+	IDPTypes []string
+	// CertExpiryWarning is how long before the gateway certificate expires
+	// the certificate expiry check starts warning.
+	CertExpiryWarning time.Duration
+	// AdminPort and ReplicationPort are the gateway ports the admin API and
+	// the replication config (on the S3 data port) are served on.
+	AdminPort       int
+	ReplicationPort int
+	// Chart selects the Helm release to diagnose by chart name, optionally
+	// with a version constraint.
+	Chart Utils.ChartSelector
+	// CapacityWarnPct and CapacityFailPct are the percentages of used
+	// capacity above which the capacity check warns and fails.
+	CapacityWarnPct float64
+	CapacityFailPct float64
+	// ExpectedVersion is a version or semver constraint, e.g. ">=1.5.0", the
+	// ObjectStore version must satisfy; empty only reports the version.
+	ExpectedVersion string
+	// ConfigFile is a YAML file setting any of the flags, keyed by flag name.
+	// Flags given on the command line take precedence.
+	ConfigFile string
+	// InitConfig writes a commented default config file to this path and
+	// exits; Force allows it to replace an existing file.
+	InitConfig string
+	Force      bool
+	// CheckTimeout bounds how long a single check may run before it fails
+	// as timed out. 0 disables the limit.
+	CheckTimeout time.Duration
+	// Username and Password log in to the gateway when no token is given.
+	// They default to the OSTORE_USER and OSTORE_PASSWORD environment
+	// variables, which keep the password out of the process list.
+	Username string
+	Password string
+	// Checks limits the run to the named checks and Skip leaves the named
+	// checks out. ListChecks prints every check name and exits.
+	Checks     []string
+	Skip       []string
+	ListChecks bool
+}
+
+func parseFlags() Config {
+	var cfg Config
+	var allowedRegistries, requiredNetworkPolicies, endpointOverrides string
+	var diskStatusesOK, diskStatusesTransient, expectedLabels, latestChartVersions string
+	var onlyChecks, skipChecks, idpTypes string
+
+	flag.StringVar(&allowedRegistries, "allowed-registries", "", "Comma-separated list of image registries ostore pods may pull from (empty disables the check)")
+	flag.BoolVar(&cfg.ProbeIngress, "probe-ingress", false, "Dial every gateway LoadBalancer ingress entry on the admin port to detect stale addresses")
+	flag.StringVar(&cfg.StateFile, "state-file", "", "Path of a file used to persist observations between runs (empty disables run-over-run checks)")
+	flag.Float64Var(&cfg.MaxObjectDropPct, "max-object-drop-pct", 10, "Largest drop in bucket/object count, in percent, tolerated between runs")
+	flag.BoolVar(&cfg.RedactEndpoints, "redact-endpoints", false, "Replace IPs and hostnames in all output with stable placeholders such as gateway-1")
+	flag.IntVar(&cfg.Parallelism, "parallelism", 4, "Maximum number of checks run concurrently (1 runs them serially)")
+	flag.StringVar(&cfg.ExpectedECScheme, "expected-ec-scheme", "", "Minimum redundancy scheme every diskset must provide, e.g. 4+2 (empty only reports the schemes)")
+	flag.StringVar(&cfg.OIDCTokenFile, "oidc-token-file", "", "File holding a short-lived OIDC token to authenticate with instead of logging in; re-read when the gateway answers 401")
+	flag.StringVar(&requiredNetworkPolicies, "required-network-policies", "", "Comma-separated list of NetworkPolicies that must exist in the ostore namespace (empty disables the check)")
+	flag.BoolVar(&cfg.Watch, "watch", false, "Re-run the diagnostic continuously until interrupted")
+	flag.DurationVar(&cfg.WatchInterval, "interval", time.Minute, "Delay between runs in --watch and --serve mode")
+	flag.BoolVar(&cfg.Serve, "serve", false, "Re-run the diagnostic every --interval and expose the results as Prometheus metrics at /metrics until interrupted")
+	flag.StringVar(&cfg.Listen, "listen", ":8080", "Address the --serve metrics endpoint listens on")
+	flag.IntVar(&cfg.BreakerThreshold, "breaker-threshold", 3, "Consecutive failures after which a check is skipped in --watch and --serve mode (0 disables)")
+	flag.DurationVar(&cfg.BreakerCooldown, "breaker-cooldown", 5*time.Minute, "How long a check stays skipped once its circuit opens in --watch and --serve mode")
+	flag.StringVar(&cfg.FieldMap, "field-map", "", `JSON file remapping response keys per endpoint, e.g. {"disk.status_str": "statusStr"}`)
+	flag.StringVar(&endpointOverrides, "endpoint-override", "", "Comma-separated name=path pairs relocating gateway API endpoints, e.g. cluster_health=/v2/cluster_health")
+	flag.BoolVar(&cfg.SecurityChecks, "security-checks", false, "Run security posture checks, such as verifying the gateway rejects unauthenticated requests")
+	flag.BoolVar(&cfg.AllContexts, "all-contexts", false, "Run the diagnostic against every context in the kubeconfig and print a per-cluster rollup")
+	flag.StringVar(&diskStatusesOK, "disk-statuses-ok", "IN_USE,UNUSED", "Comma-separated disk statuses considered healthy")
+	flag.StringVar(&diskStatusesTransient, "disk-statuses-transient", "INITIALIZING,DRAINING", "Comma-separated disk statuses reported as warnings while a disk is provisioned or drained")
+	flag.StringVar(&cfg.LoginPath, "login-path", "/user", "Gateway path used to log in and obtain a token, e.g. /auth or /login")
+	flag.BoolVar(&cfg.FailOnZombieNodes, "fail-on-zombie-nodes", false, "Fail the node check when a node is stuck in a decommission or removal state instead of warning")
+	flag.IntVar(&cfg.MaxRebuildRuns, "max-rebuild-runs", 0, "Consecutive runs a diskset may be seen REBUILDING before it is reported as stuck (0 disables; needs --state-file)")
+	flag.DurationVar(&cfg.MaxRebuildDuration, "max-rebuild-duration", 24*time.Hour, "How long a diskset may stay REBUILDING before it is reported as stuck (0 disables; needs --state-file)")
+	flag.StringVar(&expectedLabels, "expected-labels", "", "Comma-separated key=value labels every ostore Deployment and StatefulSet must carry, e.g. app.kubernetes.io/managed-by=Helm (empty value only requires the key; empty disables the check)")
+	flag.BoolVar(&cfg.KubeconfigFromStdin, "kubeconfig-from-stdin", false, "Read the kubeconfig from stdin instead of ~/.kube/config, e.g. when it is fetched from a secret store")
+	flag.BoolVar(&cfg.InCluster, "in-cluster", false, "Use the pod's service account instead of a kubeconfig, e.g. when run as a Job or CronJob (detected automatically when ~/.kube/config does not exist)")
+	flag.StringVar(&cfg.RunID, "run-id", "", "Identifier printed in the header and included in reports (default: generated per run)")
+	flag.StringVar(&cfg.Title, "title", "Starting Object Store Diagnose", "Banner printed at the start of each run")
+	flag.BoolVar(&cfg.VerifyTLS, "verify-tls", false, "Verify the gateway's TLS certificate and check that its SANs cover the endpoint used")
+	flag.BoolVar(&cfg.DumpValues, "dump-values", false, "Print the Helm release's user-supplied values, with credentials redacted")
+	flag.BoolVar(&cfg.FailOnLatestTag, "fail-on-latest-tag", true, "Fail when an ostore container runs the latest tag or an untagged image (false only warns)")
+	flag.Var((*repeatedFlag)(&cfg.Outputs), "output", "Output as format or format:path, repeatable; formats are human (or text), audit, oneline, json, junit and prometheus-textfile, a bare format writes to stdout (default human)")
+	flag.StringVar(&cfg.TextfileDir, "textfile-dir", "/var/lib/node_exporter/textfile_collector", "Directory scraped by node_exporter's textfile collector, used with a bare --output=prometheus-textfile")
+	flag.IntVar(&cfg.MinGatewayReplicas, "min-gateway-replicas", 1, "Fewest ready gateway endpoints the gateway service may have, e.g. 2 for HA")
+	flag.DurationVar(&cfg.MaxBackupAge, "max-backup-age", 24*time.Hour, "Oldest the last successful backup may be (0 only requires a backup schedule)")
+	flag.BoolVar(&cfg.TraceHTTP, "trace-http", false, "Log DNS, connect, TLS handshake and first-byte timings of every gateway request (enables DEBUG logging)")
+	flag.IntVar(&cfg.RetryAttempts, "retry-attempts", 3, "Times a gateway request is tried in total on connection errors and 5xx responses (1 disables retries)")
+	flag.DurationVar(&cfg.RetryDelay, "retry-delay", 500*time.Millisecond, "Delay before the first retry of a gateway request, doubled for every further retry")
+	flag.StringVar(&cfg.ControlPlaneNamespace, "control-plane-namespace", Constants.KubeSystemNamespace, "Namespace of the control-plane and system pods checked by the core Kubernetes health check")
+	flag.DurationVar(&cfg.RecentRestartWindow, "recent-restart-window", 10*time.Minute, "Warn when the cluster restarted within this window (0 disables)")
+	flag.BoolVar(&cfg.NoK8s, "no-k8s", false, "Skip Helm discovery and the Kubernetes checks, running only the gateway API checks (requires --service-ip)")
+	flag.StringVar(&cfg.ServiceIP, "service-ip", "", "Gateway address to probe instead of discovering it from the gateway service")
+	flag.Float64Var(&cfg.MaxDisksetImbalancePct, "max-diskset-imbalance-pct", 50, "Warn when the least loaded node holds more than this many percent fewer disksets than the most loaded one")
+	flag.BoolVar(&cfg.AllowDisksetDecrease, "allow-diskset-decrease", false, "Only warn when the diskset count dropped since the previous run, e.g. after a planned removal (needs --state-file)")
+	flag.BoolVar(&cfg.LoadCheck, "load-check", false, "Fire a burst of concurrent requests at the gateway and check the error rate and latency (adds load)")
+	flag.IntVar(&cfg.LoadCheckRequests, "load-check-requests", 20, "Concurrent requests fired by --load-check")
+	flag.Float64Var(&cfg.LoadCheckMaxErrorPct, "load-check-max-error-pct", 5, "Largest share of --load-check requests, in percent, that may fail")
+	flag.StringVar(&cfg.TimeSyncDaemonSet, "time-sync-daemonset", "", "Time-sync DaemonSet that must be ready on every node, as namespace/name or name in --control-plane-namespace, e.g. kube-system/chrony (empty disables)")
+	flag.StringVar(&latestChartVersions, "latest-chart-versions", "", "Comma-separated chart versions known to be available; warns when one is newer than the deployed chart (empty disables)")
+	flag.IntVar(&cfg.FailThreshold, "fail-threshold", 0, "Failing checks tolerated before the cluster is reported unhealthy and the exit code is non-zero")
+	flag.BoolVar(&cfg.CriticalBypassesThreshold, "critical-bypasses-threshold", true, "Report the cluster unhealthy on any critical failure, regardless of --fail-threshold")
+	flag.StringVar(&cfg.Token, "token", "", "Gateway token issued out of band, used instead of logging in")
+	flag.StringVar(&cfg.BearerToken, "bearer-token", "", "Token sent as 'Authorization: Bearer', for a gateway behind an authenticating proxy, used instead of logging in")
+	flag.DurationVar(&cfg.StatefulSetUpdateGrace, "statefulset-update-grace", 30*time.Minute, "How long a StatefulSet rolling update may leave replicas on the old revision before it is reported as stalled")
+	flag.StringVar(&cfg.UserAgent, "user-agent", "ostore-health-check/"+version, "User-Agent sent with every gateway request, to identify the health checker in access logs")
+	flag.BoolVar(&cfg.ASCII, "ascii", false, "Print [OK]/[FAIL]/[WARN] instead of emoji status symbols")
+	flag.DurationVar(&cfg.MinReadyDuration, "min-ready-duration", 0, "Warn when a required pod became Ready less than this long ago and may still be stabilizing (0 disables)")
+	flag.Var((*byteSizeFlag)(&cfg.ExpectedUsableCapacity), "expected-usable-capacity", "Warn when the estimated usable capacity after redundancy overhead is below this size, e.g. 500Ti (0 disables)")
+	flag.StringVar(&cfg.ConfigFile, "config", "", "YAML file setting any of these flags by name; flags on the command line take precedence")
+	flag.StringVar(&cfg.InitConfig, "init-config", "", "Write a commented default config file to this path and exit")
+	flag.BoolVar(&cfg.Force, "force", false, "Let --init-config overwrite an existing file")
+	flag.DurationVar(&cfg.CheckTimeout, "check-timeout", 30*time.Second, "How long a single check may run before it fails as timed out (0 disables)")
+	flag.StringVar(&cfg.Username, "username", "", "Gateway username to log in with (default $OSTORE_USER)")
+	flag.StringVar(&cfg.Password, "password", "", "Gateway password to log in with (default $OSTORE_PASSWORD, which keeps it out of the process list)")
+	flag.StringVar(&onlyChecks, "checks", "", "Comma-separated list of checks to run, e.g. disk,diskset,replication (empty runs all; see --list-checks)")
+	flag.StringVar(&skipChecks, "skip", "", "Comma-separated list of checks to leave out")
+	flag.BoolVar(&cfg.ListChecks, "list-checks", false, "Print the name of every check and exit")
+	flag.Var((*versionConstraintFlag)(&cfg.ExpectedVersion), "expected-version", "Fail the version check unless the ObjectStore version matches this version or semver constraint, e.g. 1.5.2 or \">=1.5.0, <2.0.0\" (empty only reports it)")
+	flag.IntVar(&cfg.AdminPort, "admin-port", 9001, "Gateway port serving the admin API used by most checks")
+	flag.IntVar(&cfg.ReplicationPort, "replication-port", 9000, "Gateway port serving the replication config, the S3 data port")
+	flag.DurationVar(&cfg.CertExpiryWarning, "cert-expiry-warning", 30*24*time.Hour, "Warn when the gateway TLS certificate expires within this long")
+	flag.StringVar(&cfg.ReportFile, "report-file", "", "Also write the complete report to this file, e.g. for auditing (empty disables)")
+	flag.StringVar(&cfg.ReportFormat, "report-format", "audit", "Format of --report-file: audit (plain text with every check), or any --output format")
+	flag.BoolVar(&cfg.ReportAppend, "report-append", false, "Append to --report-file instead of replacing it, so repeated runs build a history")
+	flag.StringVar(&idpTypes, "idp-types", "ldap,oidc,saml", "Comma-separated identity provider types the idp check queries, each reported independently")
+	flag.Float64Var(&cfg.CapacityWarnPct, "capacity-warn-pct", 80, "Warn when more than this percentage of the cluster's capacity is used")
+	flag.Float64Var(&cfg.CapacityFailPct, "capacity-fail-pct", 90, "Fail when more than this percentage of the cluster's capacity is used")
+	cfg.Chart, _ = Utils.ParseChartSelector(Constants.HelmChart)
+	flag.Var((*chartFlag)(&cfg.Chart), "chart", "Chart of the Helm release to diagnose: a name for any version, name@constraint such as ostore@>=1.5.0, or name-version for an exact version")
+	flag.BoolVar(&cfg.Verbose, "verbose", false, "Log DEBUG messages too, such as every node, disk and pod a check looked at")
+	flag.BoolVar(&cfg.Quiet, "quiet", false, "Log only warnings and errors and print only the final verdict")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage of %s:\n", os.Args[0])
+		flag.PrintDefaults()
+		fmt.Fprint(flag.CommandLine.Output(), "\n"+exitCodeHelp)
+	}
+	flag.Parse()
+
+	if cfg.ConfigFile != "" {
+		if err := loadConfigFile(cfg.ConfigFile); err != nil {
+			fatalf("Error loading config file: %v", err)
+		}
+	}
+
+	if cfg.Username == "" {
+		cfg.Username = os.Getenv("OSTORE_USER")
+	}
+	if cfg.Password == "" {
+		cfg.Password = os.Getenv("OSTORE_PASSWORD")
+	}
+
+	cfg.AllowedRegistries = splitList(allowedRegistries)
+	cfg.RequiredNetworkPolicies = splitList(requiredNetworkPolicies)
+	cfg.EndpointOverrides = splitMap(endpointOverrides)
+	if _, found := cfg.EndpointOverrides["login"]; !found {
+		cfg.EndpointOverrides["login"] = cfg.LoginPath
+	}
+	cfg.DiskStatusesOK = splitList(diskStatusesOK)
+	cfg.DiskStatusesTransient = splitList(diskStatusesTransient)
+	cfg.ExpectedLabels = splitMap(expectedLabels)
+	cfg.LatestChartVersions = splitList(latestChartVersions)
+	cfg.Checks = splitList(onlyChecks)
+	cfg.Skip = splitList(skipChecks)
+	cfg.IDPTypes = splitList(idpTypes)
+	if len(cfg.Outputs) == 0 {
+		cfg.Outputs = []string{"human"}
+	}
+	for _, pct := range []float64{cfg.CapacityWarnPct, cfg.CapacityFailPct} {
+		if pct < 0 || pct > 100 {
+			fatalf("Invalid capacity threshold %g%%, must be between 0 and 100", pct)
+		}
+	}
+	if cfg.CapacityWarnPct >= cfg.CapacityFailPct {
+		fatalf("--capacity-warn-pct (%g) must be lower than --capacity-fail-pct (%g)", cfg.CapacityWarnPct, cfg.CapacityFailPct)
+	}
+	if cfg.LoadCheckRequests < 1 {
+		fatalf("Invalid --load-check-requests %d, must be at least 1", cfg.LoadCheckRequests)
+	}
+	if cfg.LoadCheckMaxErrorPct < 0 || cfg.LoadCheckMaxErrorPct > 100 {
+		fatalf("Invalid --load-check-max-error-pct %g%%, must be between 0 and 100", cfg.LoadCheckMaxErrorPct)
+	}
+	return cfg
+}
+
+// repeatedFlag collects every value of a flag that may be given more than
+// once.
+type repeatedFlag []string
+
+func (f *repeatedFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *repeatedFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// byteSizeFlag parses a size flag given as a Kubernetes quantity such as
+// "500Ti" or "2P" into bytes.
+type byteSizeFlag int64
+
+func (f *byteSizeFlag) String() string {
+	return strconv.FormatInt(int64(*f), 10)
+}
+
+func (f *byteSizeFlag) Set(value string) error {
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return err
+	}
+	*f = byteSizeFlag(quantity.Value())
+	return nil
+}
+
+// chartFlag parses a --chart value into a chart selector.
+type chartFlag Utils.ChartSelector
+
+func (f *chartFlag) String() string {
+	return Utils.ChartSelector(*f).String()
+}
+
+func (f *chartFlag) Set(value string) error {
+	selector, err := Utils.ParseChartSelector(value)
+	if err != nil {
+		return err
+	}
+	*f = chartFlag(selector)
+	return nil
+}
+
+// versionConstraintFlag holds a semver constraint, rejected at startup when
+// it doesn't parse.
+type versionConstraintFlag string
+
+func (f *versionConstraintFlag) String() string {
+	return string(*f)
+}
+
+func (f *versionConstraintFlag) Set(value string) error {
+	if value == "" {
+		*f = ""
+		return nil
+	}
+	if _, err := semver.NewConstraint(value); err != nil {
+		return err
+	}
+	*f = versionConstraintFlag(value)
+	return nil
+}
+
+// splitList turns a comma-separated flag value into a slice, dropping empty
+// entries and surrounding whitespace.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// splitMap turns a comma-separated list of key=value pairs into a map.
+// Entries without '=' are ignored.
+func splitMap(value string) map[string]string {
+	items := map[string]string{}
+	for _, item := range splitList(value) {
+		if key, val, found := strings.Cut(item, "="); found {
+			items[strings.TrimSpace(key)] = strings.TrimSpace(val)
+		}
+	}
+	return items
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// configFileFlags are the flags that select or generate a config file, which
+// make no sense inside one.
+var configFileFlags = []string{"config", "init-config", "force"}
+
+// writeDefaultConfig writes a YAML config file holding every tunable at its
+// default, each preceded by its flag's description. It is generated from the
+// flags that populate Config, so it always covers the full flag surface. An
+// existing file is only replaced with force.
+func writeDefaultConfig(path string, force bool) error {
+	var b strings.Builder
+	b.WriteString("# ostore health check configuration, generated with --init-config.\n")
+	b.WriteString("# Every key is a command-line flag and is shown at its default; pass this\n")
+	b.WriteString("# file with --config. Flags given on the command line take precedence.\n")
+	flag.VisitAll(func(f *flag.Flag) {
+		if isConfigFileFlag(f.Name) {
+			return
+		}
+		fmt.Fprintf(&b, "\n# %s\n%s: %s\n", f.Usage, f.Name, yamlDefault(f))
+	})
+
+	mode := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if force {
+		mode = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	file, err := os.OpenFile(path, mode, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("config file '%s' already exists, pass --force to overwrite it", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create config file '%s': %w", path, err)
+	}
+	if _, err := file.WriteString(b.String()); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write config file '%s': %w", path, err)
+	}
+	return file.Close()
+}
+
+// yamlDefault renders a flag's default as a YAML value: numbers and booleans
+// bare, repeated flags as a list and everything else quoted.
+func yamlDefault(f *flag.Flag) string {
+	if _, repeated := f.Value.(*repeatedFlag); repeated {
+		return "[]"
+	}
+	if getter, ok := f.Value.(flag.Getter); ok {
+		switch getter.Get().(type) {
+		case bool, int, int64, uint, uint64, float64:
+			return f.DefValue
+		case time.Duration:
+			return strconv.Quote(f.DefValue)
+		}
+	}
+	return strconv.Quote(f.DefValue)
+}
+
+// loadConfigFile applies the flags set in the YAML config file at path,
+// except those already given on the command line.
+func loadConfigFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file '%s': %w", path, err)
+	}
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("failed to parse config file '%s': %w", path, err)
+	}
+
+	explicit := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+	for name, value := range values {
+		f := flag.Lookup(name)
+		if f == nil || isConfigFileFlag(name) {
+			return fmt.Errorf("unknown setting '%s' in config file '%s'", name, path)
+		}
+		if explicit[name] || value == nil {
+			continue
+		}
+		settings := []string{yamlScalar(value)}
+		if items, isList := value.([]interface{}); isList {
+			settings = make([]string, len(items))
+			for i, item := range items {
+				settings[i] = yamlScalar(item)
+			}
+			// Only a repeated flag keeps every value it is set to; any other flag would end up with the last
+			// item, so it gets the comma-separated list it splits itself.
+			if _, repeated := f.Value.(*repeatedFlag); !repeated {
+				settings = []string{strings.Join(settings, ",")}
+			}
+		}
+		for _, setting := range settings {
+			if err := flag.Set(name, setting); err != nil {
+				return fmt.Errorf("invalid value for '%s' in config file '%s': %w", name, path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// yamlScalar turns a decoded YAML value back into flag syntax. Whole numbers
+// are decoded as float64 and are written without an exponent so integer
+// flags accept them.
+func yamlScalar(value interface{}) string {
+	if number, ok := value.(float64); ok {
+		return strconv.FormatFloat(number, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+func isConfigFileFlag(name string) bool {
+	return slices.Contains(configFileFlags, name)
+}
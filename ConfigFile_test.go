@@ -0,0 +1,30 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLoadConfigFileLists(t *testing.T) {
+	list := flag.String("test-list", "", "comma-separated test setting")
+	var repeated repeatedFlag
+	flag.Var(&repeated, "test-repeated", "repeated test setting")
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("test-list: [a, b]\ntest-repeated: [c, d]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := loadConfigFile(path); err != nil {
+		t.Fatalf("loadConfigFile: %v", err)
+	}
+
+	if *list != "a,b" {
+		t.Errorf("test-list = %q, want %q", *list, "a,b")
+	}
+	if want := []string{"c", "d"}; !slices.Equal(repeated, want) {
+		t.Errorf("test-repeated = %q, want %q", repeated, want)
+	}
+}
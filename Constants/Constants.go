@@ -2,7 +2,7 @@ package constants
 
 const (
 	KubeSystemNamespace = "kube-system"
-	HelmChart           = "ostore-1.5.0"
+	HelmChart           = "ostore"
 
 	// ANSI Color Codes
 	Reset          = "\x1b[0m"
@@ -16,3 +16,28 @@ const (
 	Differentiator = "=========================================================================="
 	BoldRed        = Bold + FgRed
 )
+
+// SupportedKubernetesVersions maps chart selectors, in the form --chart takes
+// such as "ostore@~1.5.0", to the inclusive range of Kubernetes major.minor
+// versions the matching chart versions support.
+var SupportedKubernetesVersions = map[string][2]string{
+	HelmChart + "@~1.5.0": {"1.24", "1.32"},
+}
+
+// Status symbols prefixed to log lines and check details. UseASCIISymbols
+// swaps them for plain-text tags on terminals that cannot render emoji.
+var (
+	SymbolOK   = "✅"
+	SymbolFail = "❌"
+	SymbolWarn = "⚠️"
+	SymbolInfo = "ℹ️"
+)
+
+// UseASCIISymbols replaces the emoji status symbols with [OK], [FAIL], [WARN]
+// and [INFO]. It must be called before any checks run.
+func UseASCIISymbols() {
+	SymbolOK = "[OK]"
+	SymbolFail = "[FAIL]"
+	SymbolWarn = "[WARN]"
+	SymbolInfo = "[INFO]"
+}
@@ -1,39 +1,506 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
+	"net/url"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"sort"
+	"syscall"
 	"time"
 
 	Check "Detective/Checks"
 	Constants "Detective/Constants"
+	Logger "Detective/Logger"
+	Report "Detective/Report"
 	Utils "Detective/Utils"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/tools/clientcmd"
 )
 
+// version is the tool's version, set at build time with -ldflags "-X main.version=<version>".
+var version = "dev"
+
 func main() {
+	os.Exit(runMain())
+}
+
+// runMain runs the tool and returns its exit code, so deferred cleanup runs before the process exits.
+func runMain() int {
+	cfg := parseFlags()
+	if cfg.InitConfig != "" {
+		if err := writeDefaultConfig(cfg.InitConfig, cfg.Force); err != nil {
+			fatalf("Error writing config file: %v", err)
+		}
+		log.Printf("Wrote default config to %s", cfg.InitConfig)
+		return exitHealthy
+	}
+	if cfg.ListChecks {
+		listChecks(os.Stdout, checks())
+		return exitHealthy
+	}
+	selected, err := selectChecks(checks(), cfg.Checks, cfg.Skip)
+	if err != nil {
+		fatalf("Error selecting checks: %v", err)
+	}
+	if cfg.NoK8s {
+		selected = apiChecks(selected)
+	}
+
+	stdout := io.Writer(os.Stdout)
+	var redactor *Utils.Redactor
+	if cfg.RedactEndpoints {
+		redactor = Utils.NewRedactor()
+		stdout = redactor.Writer(os.Stdout)
+		log.SetOutput(redactor.Writer(os.Stderr))
+	}
+
+	if cfg.FieldMap != "" {
+		if err := Utils.LoadFieldOverrides(cfg.FieldMap); err != nil {
+			fatalf("Error loading field map: %v", err)
+		}
+	}
+
+	if err := Utils.SetEndpointOverrides(cfg.EndpointOverrides); err != nil {
+		fatalf("Error applying endpoint overrides: %v", err)
+	}
+	if err := Utils.SetPorts(cfg.AdminPort, cfg.ReplicationPort); err != nil {
+		fatalf("Error applying gateway ports: %v", err)
+	}
+	Utils.SetVerifyTLS(cfg.VerifyTLS)
+	Utils.SetUserAgent(cfg.UserAgent)
+	Utils.SetRetry(cfg.RetryAttempts, cfg.RetryDelay)
+	if cfg.ASCII {
+		Constants.UseASCIISymbols()
+	}
+	if cfg.Verbose && cfg.Quiet {
+		fatalf("Only one of --verbose and --quiet may be given")
+	}
+	switch {
+	case cfg.Verbose || cfg.TraceHTTP:
+		Logger.SetLevel(Logger.LevelDebug)
+	case cfg.Quiet:
+		Logger.SetLevel(Logger.LevelWarn)
+	}
+	Logger.FilterStandardLog()
+	if cfg.TraceHTTP {
+		Utils.SetTraceHTTP(true)
+	}
+
+	if _, err := parseOutputs(cfg, ""); err != nil {
+		fatalf("Error parsing outputs: %v", err)
+	}
+
+	if cfg.InCluster && (cfg.KubeconfigFromStdin || cfg.AllContexts) {
+		fatalf("--in-cluster cannot be combined with --kubeconfig-from-stdin or --all-contexts")
+	}
+	kubeconfig := Utils.KubeconfigSource{Path: kubeconfigPath()}
+	switch {
+	case cfg.KubeconfigFromStdin:
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fatalf("Error reading kubeconfig from stdin: %v", err)
+		}
+		kubeconfig = Utils.KubeconfigSource{Data: data}
+	case cfg.InCluster || (!cfg.AllContexts && !fileExists(kubeconfig.Path) && Utils.RunningInCluster()):
+		kubeconfig = Utils.KubeconfigSource{InCluster: true}
+	}
+
+	authModes := 0
+	for _, value := range []string{cfg.OIDCTokenFile, cfg.Token, cfg.BearerToken} {
+		if value != "" {
+			authModes++
+		}
+	}
+	if authModes > 1 {
+		fatalf("Only one of --oidc-token-file, --token and --bearer-token may be given")
+	}
+	if authModes == 0 && (cfg.Username == "" || cfg.Password == "") && needsAuthentication(selected) {
+		fatalf("No gateway credentials: set OSTORE_USER and OSTORE_PASSWORD (or pass --username and --password), or authenticate with --token, --bearer-token or --oidc-token-file")
+	}
+
+	if cfg.NoK8s {
+		if cfg.ServiceIP == "" {
+			fatalf("--no-k8s requires --service-ip")
+		}
+		if cfg.AllContexts {
+			fatalf("--no-k8s cannot be combined with --all-contexts")
+		}
+	}
+
+	if cfg.Serve && (cfg.Watch || cfg.AllContexts) {
+		fatalf("--serve cannot be combined with --watch or --all-contexts")
+	}
+
+	if cfg.AllContexts {
+		return runAllContexts(cfg, kubeconfig, stdout, redactor)
+	}
+
+	if cfg.Serve {
+		// Like watch mode, a check that keeps failing is skipped for a cool-down period.
+		breaker := Utils.NewCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown)
+		return serve(cfg, func(ctx context.Context) Report.Report {
+			start := time.Now()
+			_, results, err := run(ctx, cfg, kubeconfig, "", stdout, redactor, breaker)
+			report := Report.Report{Started: start, Elapsed: time.Since(start), Results: results, Err: err}
+			report.Healthy, report.Verdict = verdict(results, err, cfg.FailThreshold, cfg.CriticalBypassesThreshold)
+			return report
+		})
+	}
+
+	if !cfg.Watch {
+		ctx, stop := interruptContext()
+		defer stop()
+		_, results, err := run(ctx, cfg, kubeconfig, "", stdout, redactor, nil)
+		return exitCode(results, err, cfg)
+	}
+
+	// In watch mode a check that keeps failing is skipped for a cool-down period instead of being
+	// hammered on every run.
+	breaker := Utils.NewCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown)
+	return watch(cfg, func(ctx context.Context) []Check.CheckResult {
+		// A run that couldn't complete is retried on the next cycle rather than ending the watch.
+		_, results, _ := run(ctx, cfg, kubeconfig, "", stdout, redactor, breaker)
+		return results
+	}, progressWriter(cfg, stdout))
+}
+
+// The process exit codes, listed in --help. A run cut short by SIGINT or SIGTERM follows the shell's
+// 128+SIGINT convention.
+const (
+	exitHealthy     = 0
+	exitUnhealthy   = 1
+	exitSetup       = 2
+	exitInterrupted = 130
+)
+
+// exitCodeHelp documents the exit codes for --help.
+const exitCodeHelp = `Exit codes:
+  0    the cluster is healthy
+  1    the cluster is unhealthy: checks failed beyond --fail-threshold, a login or fatal check failed
+  2    setup error: invalid flags or config, kubeconfig, Helm release or gateway address not found
+  130  interrupted by SIGINT or SIGTERM
+`
+
+// exitCode returns the exit code for a run that ended with results and err.
+func exitCode(results []Check.CheckResult, err error, cfg Config) int {
+	switch Utils.ErrorCategory(err) {
+	case "interrupt":
+		return exitInterrupted
+	case "setup", "discovery":
+		return exitSetup
+	}
+	if healthy, _ := verdict(results, err, cfg.FailThreshold, cfg.CriticalBypassesThreshold); !healthy {
+		return exitUnhealthy
+	}
+	return exitHealthy
+}
+
+// fatalf logs a startup error and exits with exitSetup.
+func fatalf(format string, args ...interface{}) {
+	Logger.Errorf(context.Background(), format, args...)
+	os.Exit(exitSetup)
+}
+
+// interruptContext returns a context cancelled on the first SIGINT or SIGTERM, so the run can stop its
+// checks and still report what finished. The signals' default handling is restored once it fires, so a
+// second one ends the process at once.
+func interruptContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
+
+// runAllContexts runs the full diagnostic against every context in the kubeconfig, one cluster at a
+// time with each run's checks bounded by --parallelism, then prints a per-cluster rollup. It returns
+// exitUnhealthy when any cluster is unhealthy or couldn't be checked.
+func runAllContexts(cfg Config, kubeconfig Utils.KubeconfigSource, stdout io.Writer, redactor *Utils.Redactor) int {
+	loaded, err := kubeconfig.Load()
+	if err != nil {
+		fatalf("Error loading kubeconfig: %v", err)
+	}
+
+	contexts := make([]string, 0, len(loaded.Contexts))
+	for name := range loaded.Contexts {
+		contexts = append(contexts, name)
+	}
+	sort.Strings(contexts)
+
+	// The cluster headers and the rollup are progress, kept off a machine-readable stdout.
+	progress := progressWriter(cfg, stdout)
+	ctx, stop := interruptContext()
+	defer stop()
+
+	type clusterOutcome struct {
+		context string
+		healthy bool
+		issues  int
+		// failure is the category of the error that aborted the cluster's run, if any.
+		failure string
+		elapsed time.Duration
+	}
+	outcomes := []clusterOutcome{}
+	for _, kubeContext := range contexts {
+		fmt.Fprint(progress, Constants.BoldGreen+"Cluster context: "+kubeContext+Constants.Reset+Constants.Newline+Constants.Differentiator+Constants.TwoNewLines)
+		clusterCfg := cfg
+		if cfg.StateFile != "" {
+			// Keep each cluster's history apart so counts aren't compared across clusters.
+			clusterCfg.StateFile = cfg.StateFile + "." + kubeContext
+		}
+		if cfg.ReportFile != "" && !cfg.ReportAppend {
+			// A replaced report file would only keep the last cluster.
+			clusterCfg.ReportFile = cfg.ReportFile + "." + kubeContext
+		}
+		start := time.Now()
+		issues, results, err := run(ctx, clusterCfg, kubeconfig, kubeContext, stdout, redactor, nil)
+		healthy, _ := verdict(results, err, cfg.FailThreshold, cfg.CriticalBypassesThreshold)
+		outcomes = append(outcomes, clusterOutcome{kubeContext, healthy, len(issues), Utils.ErrorCategory(err), time.Since(start)})
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	unhealthy := 0
+	if skipped := len(contexts) - len(outcomes); skipped > 0 {
+		log.Printf(Constants.SymbolWarn+" Interrupted, %d clusters were not checked", skipped)
+	}
+	fmt.Fprint(progress, Constants.BoldGreen+"Per-cluster summary"+Constants.Reset+Constants.Newline+Constants.Differentiator+Constants.TwoNewLines)
+	for _, outcome := range outcomes {
+		if outcome.failure != "" {
+			unhealthy++
+			fmt.Fprintf(progress, "%s"+Constants.SymbolFail+" %-30s ERROR (%s) in %s%s\n", Constants.FgRed, outcome.context, outcome.failure, outcome.elapsed.Round(time.Millisecond), Constants.Reset)
+		} else if !outcome.healthy {
+			unhealthy++
+			fmt.Fprintf(progress, "%s"+Constants.SymbolFail+" %-30s UNHEALTHY (%d issues) in %s%s\n", Constants.FgRed, outcome.context, outcome.issues, outcome.elapsed.Round(time.Millisecond), Constants.Reset)
+		} else {
+			fmt.Fprintf(progress, "%s"+Constants.SymbolOK+" %-30s HEALTHY in %s%s\n", Constants.FgGreen, outcome.context, outcome.elapsed.Round(time.Millisecond), Constants.Reset)
+		}
+	}
+	fmt.Fprint(progress, Constants.Newline)
+
+	if ctx.Err() != nil {
+		return exitInterrupted
+	}
+	if unhealthy > 0 {
+		Logger.Errorf(ctx, "%d of %d clusters are unhealthy", unhealthy, len(outcomes))
+		return exitUnhealthy
+	}
+	return exitHealthy
+}
+
+// run performs one complete diagnostic pass against the cluster selected by kubeContext (the
+// kubeconfig's current context when empty), reports it to every --output and returns the issues found
+// along with the check results. The error is set when the run was cut short or some checks couldn't
+// run, categorized as a setup, discovery, authentication, fatal check or interrupt error.
+func run(ctx context.Context, cfg Config, kubeconfig Utils.KubeconfigSource, kubeContext string, stdout io.Writer, redactor *Utils.Redactor, breaker *Utils.CircuitBreaker) ([]string, []Check.CheckResult, error) {
 	start := time.Now()
+	runID := cfg.RunID
+	if runID == "" {
+		runID = newRunID(start)
+	}
+	log.Print(Constants.BoldGreen + cfg.Title + Constants.Reset + Constants.Newline)
+	log.Print("Run ID: " + runID + Constants.TwoNewLines)
+
+	t := &target{ctx: ctx, cfg: cfg, runID: runID, kubeContext: kubeContext}
+	Issues, results, err := diagnose(t, kubeconfig, progressWriter(cfg, stdout), redactor, breaker)
+	if err != nil {
+		log.Printf(Constants.SymbolFail+" Run incomplete (%s error): %v", Utils.ErrorCategory(err), err)
+		// A fatal check's failure is already listed among the check issues.
+		if Utils.ErrorCategory(err) != "check" {
+			Issues = append(Issues, err.Error())
+		}
+	}
+
+	report := Report.Report{
+		RunID:     runID,
+		Context:   t.kubeContext,
+		APIServer: t.apiServer,
+		Gateway:   t.serviceIP,
+		Started:   start,
+		Results:   results,
+		Err:       err,
+	}
+	report.Healthy, report.Verdict = verdict(results, err, cfg.FailThreshold, cfg.CriticalBypassesThreshold)
+	outputs, _ := parseOutputs(cfg, t.kubeContext)
+	// Files are written first so a failure to write one is listed by the outputs on stdout.
+	for _, output := range outputs {
+		if output.Path == "" {
+			continue
+		}
+		report.Elapsed, report.Issues = time.Since(start), Issues
+		writeFile := Report.WriteFile
+		if output.Append {
+			writeFile = Report.AppendFile
+		}
+		err := writeFile(output.Path, func(w io.Writer) error {
+			if redactor != nil {
+				w = redactor.Writer(w)
+			}
+			return output.Reporter.Write(w, report)
+		})
+		if err != nil {
+			log.Printf(Constants.SymbolFail+" Unable to write %s output: %v", output.Format, err)
+			Issues = append(Issues, err.Error())
+		}
+	}
+	report.Elapsed, report.Issues = time.Since(start), Issues
+	for _, output := range outputs {
+		if output.Path != "" {
+			continue
+		}
+		if err := output.Reporter.Write(stdout, report); err != nil {
+			log.Printf(Constants.SymbolFail+" Unable to write %s output: %v", output.Format, err)
+		}
+	}
+
+	timeSince := time.Since(start)
+	log.Print(Constants.BoldGreen + "Total Time taken: " + fmt.Sprint(timeSince) + Constants.Reset + Constants.Newline)
+	return Issues, results, err
+}
+
+// diagnose sets up the target, logs in to the gateway and runs the checks, writing the cluster details and
+// check headers to progress. A missing gateway or a failed login doesn't stop the run: the checks that
+// need them are skipped and the others still run. It returns the issues found outside the checks and the
+// check results, along with the first error that cut the run short or kept checks from running.
+func diagnose(t *target, kubeconfig Utils.KubeconfigSource, progress io.Writer, redactor *Utils.Redactor, breaker *Utils.CircuitBreaker) ([]string, []Check.CheckResult, error) {
+	cfg := t.cfg
+	Issues := []string{}
+	// failure is the first error that kept part of the checks from running.
+	var failure error
+
+	t.state = &Utils.State{}
+	if cfg.StateFile != "" {
+		loaded, err := Utils.LoadState(cfg.StateFile)
+		if err != nil {
+			return Issues, nil, &Utils.SetupError{Err: fmt.Errorf("error loading state file: %w", err)}
+		}
+		t.state = loaded
+	}
+
+	// The selection was validated at startup.
+	scheduled, _ := selectChecks(checks(), cfg.Checks, cfg.Skip)
+	if cfg.NoK8s {
+		// Without Kubernetes access the gateway given on the command line stands in for the cluster.
+		t.serviceIP = cfg.ServiceIP
+		t.kubeContext = cfg.ServiceIP
+		if redactor != nil {
+			redactor.Register(cfg.ServiceIP, "gateway")
+		}
+		fmt.Fprint(progress, "Gateway: "+cfg.ServiceIP+" (Kubernetes checks skipped)"+Constants.TwoNewLines)
+		scheduled = apiChecks(scheduled)
+	} else {
+		preflight, err := discoverCluster(t, kubeconfig, t.kubeContext, progress, redactor)
+		Issues = append(Issues, preflight...)
+		if err != nil && t.clientset == nil {
+			return Issues, nil, err
+		}
+		if err != nil {
+			// The cluster was found but not its gateway, so the Kubernetes checks can still run.
+			log.Printf(Constants.SymbolFail+" %v, running the checks that don't need the gateway", err)
+			failure = err
+		}
+	}
+
+	if t.serviceIP != "" && needsAuthentication(scheduled) {
+		// The run logs in once; the checks share the token until the gateway rejects it.
+		auth := Utils.NewCachedAuthenticator(newAuthenticator(cfg, t.serviceIP))
+		if _, err := auth.Token(t.ctx); err != nil {
+			log.Printf(Constants.SymbolFail+" Obtaining a gateway token FAILED: %v, running the checks that don't need it", err)
+			failure = &Utils.AuthError{Err: fmt.Errorf("obtaining a gateway token FAILED: %w", err)}
+		} else {
+			t.auth = auth
+		}
+	}
+
+	// Strict checks would raise expected failures during planned maintenance, so say so up front.
+	if t.auth != nil {
+		if state, upgrading, _ := Check.UpgradeState(t.ctx, t.auth, t.serviceIP); upgrading {
+			t.upgrade = state
+			fmt.Fprint(progress, Constants.Bold+Constants.FgYellow+Constants.SymbolWarn+" Cluster is upgrading ("+state+"): component failures are reported as warnings"+Constants.Reset+Constants.TwoNewLines)
+		}
+	}
+
+	results, err := runChecks(progress, scheduled, t, cfg.Parallelism, breaker)
+	for _, result := range results {
+		if !result.OK && result.Outcome() != Check.StatusCancelled {
+			Issues = append(Issues, result.Detail)
+		}
+	}
+	// An interrupt decides the exit code, otherwise the earliest failure explains the incomplete run.
+	if err != nil && (failure == nil || Utils.ErrorCategory(err) == "interrupt") {
+		failure = err
+	}
+	if failure != nil {
+		return Issues, results, failure
+	}
+
+	if cfg.StateFile != "" {
+		if err := Utils.SaveState(cfg.StateFile, t.state); err != nil {
+			log.Printf(Constants.SymbolFail+" Unable to save state file: %v", err)
+			Issues = append(Issues, err.Error())
+		}
+	}
+	return Issues, results, nil
+}
+
+// newAuthenticator returns the Authenticator selected by the auth flags, falling back to logging in
+// with a username and password.
+func newAuthenticator(cfg Config, serviceIP string) Utils.Authenticator {
+	switch {
+	case cfg.OIDCTokenFile != "":
+		return Utils.TokenFileAuthenticator{Path: cfg.OIDCTokenFile}
+	case cfg.Token != "":
+		return Utils.StaticTokenAuthenticator{Value: cfg.Token}
+	case cfg.BearerToken != "":
+		return Utils.BearerAuthenticator{Value: cfg.BearerToken}
+	}
+	return Utils.PasswordAuthenticator{ServiceIP: serviceIP, Username: cfg.Username, Password: cfg.Password}
+}
+
+// discoverCluster connects to the cluster selected by kubeContext, finds the ostore Helm release and
+// the gateway service, and fills in the target's Kubernetes side. It returns the preflight issues
+// found along the way, or the setup or discovery error that stopped it. When only the gateway's address
+// couldn't be found the target's clientset is set, so the Kubernetes checks can still run.
+func discoverCluster(t *target, kubeconfig Utils.KubeconfigSource, kubeContext string, progress io.Writer, redactor *Utils.Redactor) ([]string, error) {
+	cfg := t.cfg
 	Issues := []string{}
-	log.Print(Constants.BoldGreen + "Starting Object Store Diagnose" + Constants.Reset + Constants.TwoNewLines)
 
 	// Set up kubernetes client
-	config, err := clientcmd.BuildConfigFromFlags("", filepath.Join(homedir(), ".kube", "config"))
+	config, err := Utils.BuildKubeConfig(kubeconfig, kubeContext)
 	if err != nil {
-		log.Fatalf("Error building kubeconfig: %v", err)
+		return Issues, &Utils.SetupError{Err: fmt.Errorf("error building kubeconfig: %w", err)}
 	}
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		log.Fatalf("Error creating clientset: %v", err)
+		return Issues, &Utils.SetupError{Err: fmt.Errorf("error creating clientset: %w", err)}
 	}
 
 	// Identify Helm release and namespace
-	releaseName, appNamespace, err := Utils.FindHelmReleaseByChart(filepath.Join(homedir(), ".kube", "config"), Constants.HelmChart)
+	release, err := Utils.FindHelmReleaseByChart(config, cfg.Chart)
 	if err != nil {
-		log.Fatalf("Error finding Helm release: %v", err)
+		return Issues, &Utils.DiscoveryError{Err: fmt.Errorf("error finding Helm release: %w", err)}
+	}
+	releaseName, appNamespace := release.Name, release.Namespace
+
+	if cfg.DumpValues {
+		values, err := json.MarshalIndent(Utils.RedactValues(release.Config), "", "  ")
+		if err != nil {
+			log.Printf(Constants.SymbolFail+" Unable to encode Helm values: %v", err)
+		} else {
+			fmt.Fprint(progress, Constants.BoldGreen+"Helm values of release "+releaseName+Constants.Reset+Constants.Newline+Constants.Differentiator+Constants.Newline)
+			fmt.Fprint(progress, string(values)+Constants.TwoNewLines)
+		}
 	}
 
 	serviceName := "ostore-gateway-server"
@@ -41,22 +508,31 @@ func main() {
 		serviceName = releaseName + "-" + "ostore-gateway-server"
 	}
 
-	// Get External IP of the service
-	serviceIP, err := Utils.GetExternalIPForService(clientset, appNamespace, serviceName)
-	if err != nil {
-		log.Fatalf("Error getting external IP for service: %v", err)
+	if redactor != nil {
+		registerEndpoints(redactor, clientset, config.Host, appNamespace, serviceName)
 	}
 
-	// Perform core cluster health check
-	fmt.Print(Constants.BoldGreen + "[1/10] Running Core Kubernetes Health Check" + Constants.Reset + Constants.Newline + Constants.Differentiator + Constants.TwoNewLines)
-	if err := Check.KubernetesHealth(clientset); err != nil {
-		log.Fatalf("❌ Core Kubernetes health check FAILED: %v", err)
+	resolvedContext, err := Utils.ResolveKubeContext(kubeconfig, kubeContext)
+	if err != nil {
+		return Issues, &Utils.SetupError{Err: fmt.Errorf("error resolving kube context: %w", err)}
 	}
+	// Record which cluster is targeted before any check runs so the report is unambiguous.
+	fmt.Fprint(progress, "Kube context: "+resolvedContext+Constants.Newline+"API server: "+config.Host+Constants.TwoNewLines)
 
-	log.Print("✅ Core Kubernetes components are healthy." + Constants.TwoNewLines)
+	// Report missing permissions up front rather than as List errors mid-run.
+	if preflight := Check.RBACPreflight(t.ctx, clientset, appNamespace, cfg.ControlPlaneNamespace); !preflight.OK {
+		log.Print(preflight.Detail + Constants.TwoNewLines)
+		Issues = append(Issues, preflight.Detail)
+	}
 
-	// Define the list of required pod prefixes for the 'ostore' namespace
-	requiredOstorePods := []string{
+	t.kubeContext = resolvedContext
+	t.apiServer = config.Host
+	t.clientset = clientset
+	t.release = release
+	t.namespace = appNamespace
+	t.serviceName = serviceName
+	// Pod prefixes that must be running in the ostore namespace
+	t.requiredPods = []string{
 		releaseName + "-gateway",
 		releaseName + "-cm",
 		releaseName + "-agent",
@@ -67,85 +543,105 @@ func main() {
 		"yb-tserver",
 	}
 
-	fmt.Print(Constants.BoldGreen + "[2/10] Running Application Pod Check for namespace: " + appNamespace + Constants.Reset + Constants.Newline + Constants.Differentiator + Constants.TwoNewLines)
-	isSuccess := Check.AllPodsAreRunning(clientset, appNamespace, requiredOstorePods)
-	if isSuccess != "Success" {
-		log.Printf("Application pod check for namespace '%s' FAILED: %v", appNamespace, isSuccess)
-		Issues = append(Issues, isSuccess)
+	// Get External IP of the service, unless one was given. The cluster side is already filled in so the
+	// Kubernetes checks can run even when the gateway can't be found.
+	serviceIP := cfg.ServiceIP
+	if serviceIP == "" {
+		serviceIP, err = Utils.GetExternalIPForService(t.ctx, clientset, appNamespace, serviceName)
+		if err != nil {
+			return Issues, &Utils.DiscoveryError{Err: fmt.Errorf("error getting external IP for service: %w", err)}
+		}
 	}
+	t.serviceIP = serviceIP
+	return Issues, nil
+}
 
-	log.Print("All required pods are present and healthy in namespace: " + appNamespace + Constants.TwoNewLines)
-	fmt.Print(Constants.BoldGreen + "[3/10] Running PersistentVolume Check " + Constants.Reset + Constants.Newline + Constants.Differentiator + Constants.TwoNewLines)
-	if err := Check.LocalPVsAreBound(clientset); err != nil {
-		log.Printf("❌ PersistentVolume check FAILED: %v", err)
-		Issues = append(Issues, err.Error())
+// progressWriter returns where the human-readable progress of a run goes: stdout, unless stdout carries
+// a machine-readable format, in which case it goes to stderr alongside the log so stdout stays
+// parseable. --quiet drops it.
+func progressWriter(cfg Config, stdout io.Writer) io.Writer {
+	if cfg.Quiet {
+		return io.Discard
 	}
-
-	token, err := Utils.TriggerPostRequestAndGetToken(serviceIP)
-	if err != nil {
-		log.Fatalf("❌ POST request FAILED: %v", err)
+	outputs, _ := parseOutputs(cfg, "")
+	for _, output := range outputs {
+		if output.Path == "" && !output.Human() {
+			return log.Writer()
+		}
 	}
+	return stdout
+}
 
-	fmt.Print(Constants.BoldGreen + "[4/10] Checking ObjectStore Version " + Constants.Reset + Constants.Newline + Constants.Differentiator + Constants.TwoNewLines)
-	isSuccess = Check.OstoreVersion(token, serviceIP)
-	if isSuccess != "Success" {
-		log.Printf("❌ Unable to get the ObjectStore Version, Reason: %v", isSuccess)
-		Issues = append(Issues, isSuccess)
+// parseOutputs parses the --output values for a run against kubeContext. A bare prometheus-textfile
+// output writes into --textfile-dir, and human output is added on stdout when every other output goes
+// to a file so an interactive run still shows its outcome.
+func parseOutputs(cfg Config, kubeContext string) ([]Report.Output, error) {
+	outputs := []Report.Output{}
+	toStdout := false
+	for _, spec := range cfg.Outputs {
+		output, err := Report.ParseOutput(spec)
+		if err != nil {
+			return nil, err
+		}
+		if output.Format == "prometheus-textfile" && output.Path == "" {
+			output.Path = filepath.Join(cfg.TextfileDir, Report.TextfileName(kubeContext))
+		}
+		toStdout = toStdout || output.Path == ""
+		outputs = append(outputs, output)
 	}
-
-	fmt.Print(Constants.BoldGreen + "[5/10] Checking Disks Status " + Constants.Reset + Constants.Newline + Constants.Differentiator + Constants.TwoNewLines)
-	isSuccess = Check.DiskStatus(token, serviceIP)
-	if isSuccess != "Success" {
-		log.Printf("❌ GET request for disk status FAILED: %v", isSuccess)
-		Issues = append(Issues, isSuccess)
+	if !toStdout {
+		human, _ := Report.ParseOutput("human")
+		outputs = append(outputs, human)
 	}
-
-	fmt.Print(Constants.BoldGreen + "[6/10] Checking Diskset Status " + Constants.Reset + Constants.Newline + Constants.Differentiator + Constants.TwoNewLines)
-	isSuccess = Check.DisksetStatus(token, serviceIP)
-	if isSuccess != "Success" {
-		log.Printf("❌ GET request for diskset status FAILED: %v", isSuccess)
-		Issues = append(Issues, isSuccess)
+	if cfg.ReportFile != "" {
+		output, err := Report.ParseOutput(cfg.ReportFormat)
+		if err != nil {
+			return nil, fmt.Errorf("--report-format: %w", err)
+		}
+		output.Path, output.Append = cfg.ReportFile, cfg.ReportAppend
+		outputs = append(outputs, output)
 	}
+	return outputs, nil
+}
 
-	fmt.Print(Constants.BoldGreen + "[7/10] Checking Node Status " + Constants.Reset + Constants.Newline + Constants.Differentiator + Constants.TwoNewLines)
-	isSuccess = Check.NodesStatus(token, serviceIP)
-	if isSuccess != "Success" {
-		log.Print(isSuccess)
-		Issues = append(Issues, isSuccess)
+// registerEndpoints pre-assigns descriptive placeholders to the cluster's well-known endpoints so a
+// redacted report reads "gateway-1" or "node-2" rather than a generic "host-1".
+func registerEndpoints(redactor *Utils.Redactor, clientset *kubernetes.Clientset, apiServer, namespace, serviceName string) {
+	if u, err := url.Parse(apiServer); err == nil {
+		redactor.Register(u.Hostname(), "apiserver")
 	}
 
-	fmt.Print(Constants.BoldGreen + "[8/10] Checking Replication Status " + Constants.Reset + Constants.Newline + Constants.Differentiator + Constants.TwoNewLines)
-	isSuccess = Check.ReplicationStatus(token, serviceIP)
-	if isSuccess != "Success" {
-		log.Print(isSuccess)
-		Issues = append(Issues, isSuccess)
+	if service, err := clientset.CoreV1().Services(namespace).Get(context.TODO(), serviceName, metav1.GetOptions{}); err == nil {
+		for _, endpoint := range append(Utils.LoadBalancerEndpoints(service), service.Spec.ExternalIPs...) {
+			redactor.Register(endpoint, "gateway")
+		}
 	}
 
-	fmt.Print(Constants.BoldGreen + "[9/10] Checking LDAP Status " + Constants.Reset + Constants.Newline + Constants.Differentiator + Constants.TwoNewLines)
-	isSuccess = Check.LDAPStatus(token, serviceIP)
-	if isSuccess != "Success" {
-		log.Print(isSuccess)
-		Issues = append(Issues, isSuccess)
+	if nodes, err := clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{}); err == nil {
+		for _, node := range nodes.Items {
+			placeholder := redactor.Register(node.Name, "node")
+			for _, address := range node.Status.Addresses {
+				redactor.Alias(address.Address, placeholder)
+			}
+		}
 	}
+}
 
-	fmt.Print(Constants.BoldGreen + "[10/10] Checking Ostore Cluster Health Status " + Constants.Reset + Constants.Newline + Constants.Differentiator + Constants.TwoNewLines)
-	isSuccess = Check.ClusterHealth(token, serviceIP)
-	if isSuccess != "Success" {
-		log.Print(isSuccess)
-		Issues = append(Issues, isSuccess)
-	}
+// newRunID returns a run ID derived from the start time, with a random suffix so runs started in the
+// same second stay distinct.
+func newRunID(start time.Time) string {
+	suffix := make([]byte, 3)
+	rand.Read(suffix)
+	return start.UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(suffix)
+}
 
-	if len(Issues) > 0 {
-		fmt.Print(Constants.BoldRed + "Issues detected during the health check:" + Constants.Reset + Constants.Newline + Constants.Differentiator + Constants.TwoNewLines)
-		for _, issue := range Issues {
-			fmt.Print(Constants.FgRed + "- " + issue + Constants.Reset)
-		}
-	} else {
-		fmt.Print(Constants.Newline + Constants.BoldGreen + "Overall check successful! Both the cluster and the Object Store application are healthy. " + Constants.Reset + Constants.Newline + Constants.Differentiator + Constants.TwoNewLines)
-	}
+func kubeconfigPath() string {
+	return filepath.Join(homedir(), ".kube", "config")
+}
 
-	timeSince := time.Since(start)
-	log.Print(Constants.BoldGreen + "Total Time taken: " + fmt.Sprint(timeSince) + Constants.Reset + Constants.Newline)
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
 }
 
 func homedir() string {
@@ -0,0 +1,174 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	Constants "Detective/Constants"
+)
+
+// Level orders log messages by importance; messages below the configured level are dropped.
+type Level int32
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var level atomic.Int32
+
+func init() {
+	level.Store(int32(LevelInfo))
+}
+
+// SetLevel sets the lowest level that is logged.
+func SetLevel(l Level) {
+	level.Store(int32(l))
+}
+
+// Enabled reports whether messages at l are logged.
+func Enabled(l Level) bool {
+	return l >= Level(level.Load())
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx whose messages go to l instead of the standard logger.
+func NewContext(ctx context.Context, l *log.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// from returns the logger carried by ctx, or the standard logger so messages share its output,
+// including redaction.
+func from(ctx context.Context) *log.Logger {
+	if l, ok := ctx.Value(contextKey{}).(*log.Logger); ok {
+		return l
+	}
+	return log.Default()
+}
+
+// Print, Printf and Println log like their counterparts in the log package, to the logger carried by
+// ctx.
+func Print(ctx context.Context, args ...interface{}) { from(ctx).Output(2, fmt.Sprint(args...)) }
+func Printf(ctx context.Context, format string, args ...interface{}) {
+	from(ctx).Output(2, fmt.Sprintf(format, args...))
+}
+func Println(ctx context.Context, args ...interface{}) { from(ctx).Output(2, fmt.Sprintln(args...)) }
+
+func logf(ctx context.Context, l Level, prefix, format string, args ...interface{}) {
+	if Enabled(l) {
+		from(ctx).Output(3, prefix+fmt.Sprintf(format, args...))
+	}
+}
+
+func Debugf(ctx context.Context, format string, args ...interface{}) {
+	logf(ctx, LevelDebug, "DEBUG ", format, args...)
+}
+func Infof(ctx context.Context, format string, args ...interface{}) {
+	logf(ctx, LevelInfo, "", format, args...)
+}
+func Warnf(ctx context.Context, format string, args ...interface{}) {
+	logf(ctx, LevelWarn, Constants.SymbolWarn+" ", format, args...)
+}
+func Errorf(ctx context.Context, format string, args ...interface{}) {
+	logf(ctx, LevelError, Constants.SymbolFail+" ", format, args...)
+}
+
+// Buffer holds the messages logged through a context returned by Buffered, so output produced
+// concurrently can be written out later in a fixed order.
+type Buffer struct {
+	mu       sync.Mutex
+	messages [][]byte
+}
+
+// Buffered returns a copy of ctx whose messages are held in the returned Buffer until it is flushed.
+func Buffered(ctx context.Context) (context.Context, *Buffer) {
+	b := &Buffer{}
+	return NewContext(ctx, log.New(b, log.Prefix(), log.Flags())), b
+}
+
+// A log.Logger hands over one whole message per Write.
+func (b *Buffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.messages = append(b.messages, append([]byte{}, p...))
+	return len(p), nil
+}
+
+// Flush writes the held messages to the standard logger's output one at a time, so its level filter
+// and redaction see each of them as if it had been logged directly.
+func (b *Buffer) Flush() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, message := range b.messages {
+		log.Writer().Write(message)
+	}
+	b.messages = nil
+}
+
+// FilterStandardLog applies the level to the plain log.Print calls as well, by wrapping the standard
+// logger's current output. Those messages carry no level, so one starting with the warning or failure
+// symbol counts as WARN or ERROR and any other as INFO.
+func FilterStandardLog() {
+	log.SetOutput(&levelWriter{out: log.Writer()})
+}
+
+type levelWriter struct {
+	out io.Writer
+}
+
+// The standard logger hands over one whole message per Write.
+func (w *levelWriter) Write(p []byte) (int, error) {
+	if Enabled(LevelInfo) || problem(string(p)) {
+		return w.out.Write(p)
+	}
+	return len(p), nil
+}
+
+// problem reports whether a formatted log message starts with the warning or failure symbol, once the
+// standard logger's prefix, leading blank lines and colour codes are stripped.
+func problem(line string) bool {
+	flags := log.Flags()
+	if flags&log.Lmsgprefix == 0 {
+		line = strings.TrimPrefix(line, log.Prefix())
+	}
+	if flags&log.Ldate != 0 && len(line) >= len("2006/01/02 ") {
+		line = line[len("2006/01/02 "):]
+	}
+	if flags&(log.Ltime|log.Lmicroseconds) != 0 {
+		header := len("15:04:05 ")
+		if flags&log.Lmicroseconds != 0 {
+			header += len(".000000")
+		}
+		if len(line) >= header {
+			line = line[header:]
+		}
+	}
+	if flags&(log.Lshortfile|log.Llongfile) != 0 {
+		if i := strings.Index(line, ": "); i >= 0 {
+			line = line[i+2:]
+		}
+	}
+	if flags&log.Lmsgprefix != 0 {
+		line = strings.TrimPrefix(line, log.Prefix())
+	}
+	for {
+		line = strings.TrimLeft(line, " \n")
+		if !strings.HasPrefix(line, "\x1b[") {
+			break
+		}
+		end := strings.IndexByte(line, 'm')
+		if end < 0 {
+			break
+		}
+		line = line[end+1:]
+	}
+	return strings.HasPrefix(line, Constants.SymbolWarn) || strings.HasPrefix(line, Constants.SymbolFail)
+}
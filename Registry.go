@@ -0,0 +1,511 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	Check "Detective/Checks"
+	Constants "Detective/Constants"
+	Logger "Detective/Logger"
+	Utils "Detective/Utils"
+
+	"helm.sh/helm/v3/pkg/release"
+	"k8s.io/client-go/kubernetes"
+)
+
+// target is everything the checks need to know about the cluster under diagnosis.
+type target struct {
+	cfg         Config
+	runID       string
+	kubeContext string
+	apiServer   string
+	clientset   *kubernetes.Clientset
+	release     *release.Release
+	namespace   string
+	serviceName string
+	serviceIP   string
+	// auth authenticates the gateway API requests of the checks. It is nil when no token could be
+	// obtained.
+	auth         Utils.Authenticator
+	state        *Utils.State
+	requiredPods []string
+	// upgrade describes the upgrade or maintenance in progress, if any. While it is set, failing
+	// checks are reported as warnings.
+	upgrade string
+	// ctx is cancelled when the run is interrupted.
+	ctx context.Context
+}
+
+// check is a single registered stage of the diagnostic. When a fatal check fails, the remaining checks
+// of the same kind are skipped since their results can't be trusted. A critical check's failure is
+// never tolerated by --fail-threshold when --critical-bypasses-threshold is set. A kubernetes check
+// needs the Kubernetes API and is left out with --no-k8s. An authenticated check calls the gateway API
+// through the target's Authenticator and is skipped when no token could be obtained. run gets a
+// context that expires after --check-timeout.
+type check struct {
+	name          string
+	title         string
+	fatal         bool
+	critical      bool
+	kubernetes    bool
+	authenticated bool
+	run           func(ctx context.Context, t *target) Check.CheckResult
+}
+
+// checks returns every check in the order they are reported. Adding a check here is all it takes for it
+// to be scheduled and numbered.
+func checks() []check {
+	return []check{
+		{name: "kubernetes", title: "Running Core Kubernetes Health Check", fatal: true, critical: true, kubernetes: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.KubernetesHealth(ctx, t.clientset, t.cfg.ControlPlaneNamespace)
+		}},
+		{name: "kubernetes-version", title: "Checking Kubernetes Version Compatibility", kubernetes: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.KubernetesVersion(ctx, t.clientset, t.release.Chart.Name(), t.release.Chart.Metadata.Version)
+		}},
+		{name: "chart-version", title: "Checking For a Newer Chart Version", kubernetes: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.ChartUpToDate(ctx, t.release, t.cfg.LatestChartVersions)
+		}},
+		{name: "pods", title: "Running Application Pod Check", kubernetes: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			result := Check.RequiredPods(ctx, t.clientset, t.namespace, t.serviceName, t.requiredPods, t.cfg.MinReadyDuration)
+			if result.OK {
+				Logger.Print(ctx, "All required pods are present and healthy in namespace: "+t.namespace+Constants.TwoNewLines)
+			}
+			return result
+		}},
+		{name: "pod-probes", title: "Checking Pod Readiness and Liveness Probes", kubernetes: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.PodProbes(ctx, t.clientset, t.namespace, t.requiredPods)
+		}},
+		{name: "pod-qos", title: "Checking Pod QoS Classes", kubernetes: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.PodQoS(ctx, t.clientset, t.namespace, t.requiredPods)
+		}},
+		{name: "image-registries", title: "Checking Container Image Registries", kubernetes: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.CheckImageRegistries(ctx, t.clientset, t.namespace, t.cfg.AllowedRegistries)
+		}},
+		{name: "image-tags", title: "Checking Container Image Tags", kubernetes: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.ImageTags(ctx, t.clientset, t.namespace, t.cfg.FailOnLatestTag)
+		}},
+		{name: "resource-labels", title: "Checking Labels on Ostore Resources", kubernetes: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.CheckResourceLabels(ctx, t.clientset, t.namespace, t.cfg.ExpectedLabels)
+		}},
+		{name: "loadbalancer-ingress", title: "Checking Gateway LoadBalancer Ingress", kubernetes: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.LoadBalancerIngress(ctx, t.clientset, t.namespace, t.serviceName, t.serviceIP, t.cfg.ProbeIngress)
+		}},
+		{name: "gateway-drain", title: "Checking Gateway Connection Draining", kubernetes: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.GatewayDrainStatus(ctx, t.clientset, t.namespace, t.serviceName)
+		}},
+		{name: "gateway-replicas", title: "Checking Gateway Replica Count", kubernetes: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.GatewayReplicas(ctx, t.clientset, t.namespace, t.serviceName, t.cfg.MinGatewayReplicas)
+		}},
+		{name: "statefulset-updates", title: "Checking StatefulSet Rolling Updates", kubernetes: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.StatefulSetUpdates(ctx, t.clientset, t.namespace, t.cfg.StatefulSetUpdateGrace)
+		}},
+		{name: "network-policies", title: "Checking NetworkPolicies", kubernetes: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.CheckNetworkPolicies(ctx, t.clientset, t.namespace, t.cfg.RequiredNetworkPolicies)
+		}},
+		{name: "pod-staleness", title: "Checking Pod Staleness", kubernetes: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.PodStaleness(ctx, t.clientset, t.namespace, t.release)
+		}},
+		{name: "yugabyte-tablets", title: "Checking Metadata Store Tablets", kubernetes: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.YugabyteTablets(ctx, t.clientset, t.namespace)
+		}},
+		{name: "time-sync", title: "Checking Time-Sync DaemonSet", kubernetes: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			namespace, name, found := strings.Cut(t.cfg.TimeSyncDaemonSet, "/")
+			if !found {
+				namespace, name = t.cfg.ControlPlaneNamespace, t.cfg.TimeSyncDaemonSet
+			}
+			return Check.TimeSyncDaemonSet(ctx, t.clientset, namespace, name)
+		}},
+		{name: "pv", title: "Running PersistentVolume Check", kubernetes: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.LocalPVsAreBound(ctx, t.clientset)
+		}},
+		{name: "pv-read-only", title: "Checking Local PV Filesystems Are Writable", critical: true, kubernetes: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.LocalPVsReadOnly(ctx, t.clientset, t.auth, t.serviceIP)
+		}},
+		{name: "helm-hooks", title: "Checking Helm Hooks", kubernetes: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.HelmHooks(ctx, t.clientset, t.release)
+		}},
+		{name: "version", title: "Checking ObjectStore Version", authenticated: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.OstoreVersion(ctx, t.auth, t.serviceIP, t.cfg.ExpectedVersion)
+		}},
+		{name: "disk", title: "Checking Disks Status", critical: true, authenticated: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			statuses := Check.DiskStatuses{Acceptable: t.cfg.DiskStatusesOK, Transient: t.cfg.DiskStatusesTransient}
+			return Check.DiskStatus(ctx, t.auth, t.serviceIP, t.clientset, statuses)
+		}},
+		{name: "diskset", title: "Checking Diskset Status", critical: true, authenticated: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			limits := Check.RebuildLimits{MaxRuns: t.cfg.MaxRebuildRuns, MaxDuration: t.cfg.MaxRebuildDuration}
+			return Check.DisksetStatus(ctx, t.auth, t.serviceIP, t.state, limits)
+		}},
+		{name: "diskset-count", title: "Checking Diskset Count Trend", critical: true, authenticated: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			if t.cfg.StateFile == "" {
+				Logger.Print(ctx, Constants.SymbolWarn+" No --state-file given, skipping diskset count trend check"+Constants.TwoNewLines)
+				return Check.CheckResult{Name: "diskset-count", OK: true, Status: Check.StatusSkip, Detail: "no state file"}
+			}
+			return Check.DisksetCount(ctx, t.auth, t.serviceIP, t.state, t.cfg.AllowDisksetDecrease)
+		}},
+		{name: "disk-membership", title: "Checking Diskset Membership", authenticated: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.DiskMembership(ctx, t.auth, t.serviceIP)
+		}},
+		{name: "diskset-nodes", title: "Checking Diskset Node Membership", authenticated: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.DisksetNodeMembership(ctx, t.auth, t.serviceIP)
+		}},
+		{name: "diskset-redundancy", title: "Checking Diskset Redundancy", authenticated: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.DisksetRedundancy(ctx, t.auth, t.serviceIP, t.cfg.ExpectedECScheme)
+		}},
+		{name: "usable-capacity", title: "Checking Raw and Usable Capacity", authenticated: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.UsableCapacity(ctx, t.auth, t.serviceIP, t.cfg.ExpectedUsableCapacity)
+		}},
+		{name: "capacity", title: "Checking Capacity Usage", authenticated: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.CapacityStatus(ctx, t.auth, t.serviceIP, t.cfg.CapacityWarnPct, t.cfg.CapacityFailPct)
+		}},
+		{name: "diskset-distribution", title: "Checking Diskset Distribution Across Nodes", authenticated: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.DisksetDistribution(ctx, t.auth, t.serviceIP, t.cfg.MaxDisksetImbalancePct)
+		}},
+		{name: "nodes", title: "Checking Node Status", authenticated: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.NodesStatus(ctx, t.auth, t.serviceIP, t.cfg.FailOnZombieNodes)
+		}},
+		{name: "replication", title: "Checking Replication Status", authenticated: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.ReplicationStatus(ctx, t.auth, t.serviceIP)
+		}},
+		{name: "replication-schedule", title: "Checking Replication Bandwidth and Schedule", authenticated: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.ReplicationSchedule(ctx, t.auth, t.serviceIP)
+		}},
+		{name: "idp", title: "Checking Identity Providers", authenticated: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.IDPStatus(ctx, t.auth, t.serviceIP, t.cfg.IDPTypes)
+		}},
+		{name: "cluster-health", title: "Checking Ostore Cluster Health Status", critical: true, authenticated: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.ClusterHealth(ctx, t.auth, t.serviceIP)
+		}},
+		{name: "listener-ports", title: "Checking Gateway Listener Ports", authenticated: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.ListenerPorts(ctx, t.auth, t.serviceIP)
+		}},
+		{name: "cluster-identity", title: "Checking Endpoints Report the Same Cluster ID", critical: true, authenticated: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.ClusterIdentity(ctx, t.auth, t.serviceIP)
+		}},
+		{name: "gateway-consistency", title: "Checking Gateway Replica Consistency", kubernetes: true, authenticated: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.GatewayConsistency(ctx, t.clientset, t.auth, t.namespace, t.serviceName)
+		}},
+		{name: "upgrade", title: "Checking Upgrade and Maintenance State", authenticated: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			if t.upgrade == "" {
+				Logger.Print(ctx, Constants.SymbolOK+" The gateway reports no upgrade or maintenance in progress"+Constants.TwoNewLines)
+				return Check.CheckResult{Name: "upgrade", OK: true}
+			}
+			Logger.Printf(ctx, Constants.SymbolWarn+" Cluster is upgrading (%s)%s", t.upgrade, Constants.TwoNewLines)
+			return Check.CheckResult{Name: "upgrade", OK: true, Status: Check.StatusWarn, Detail: "cluster is upgrading: " + t.upgrade}
+		}},
+		{name: "uptime", title: "Checking Cluster Uptime", authenticated: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.ClusterUptime(ctx, t.auth, t.serviceIP, t.cfg.RecentRestartWindow)
+		}},
+		{name: "advertised-endpoint", title: "Checking Gateway Advertised Endpoint", authenticated: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.AdvertisedEndpoint(ctx, t.auth, t.serviceIP)
+		}},
+		{name: "tls-san", title: "Checking Gateway TLS Certificate SANs", run: func(ctx context.Context, t *target) Check.CheckResult {
+			if !t.cfg.VerifyTLS {
+				Logger.Print(ctx, Constants.SymbolInfo+" --verify-tls not set, skipping TLS SAN check"+Constants.TwoNewLines)
+				return Check.CheckResult{Name: "tls-san", OK: true, Status: Check.StatusSkip, Detail: "TLS verification disabled"}
+			}
+			return Check.TLSSubjectAltNames(ctx, t.serviceIP)
+		}},
+		{name: "certificate-expiry", title: "Checking Gateway TLS Certificate Expiry", run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.CertificateExpiry(ctx, t.serviceIP, t.cfg.CertExpiryWarning)
+		}},
+		{name: "unauthenticated-access", title: "Checking Gateway Rejects Unauthenticated Requests", run: func(ctx context.Context, t *target) Check.CheckResult {
+			if !t.cfg.SecurityChecks {
+				Logger.Print(ctx, Constants.SymbolWarn+" --security-checks not set, skipping unauthenticated access check"+Constants.TwoNewLines)
+				return Check.CheckResult{Name: "unauthenticated-access", OK: true, Status: Check.StatusSkip, Detail: "security checks disabled"}
+			}
+			return Check.UnauthenticatedAccess(ctx, t.serviceIP)
+		}},
+		{name: "load", title: "Checking Gateway Under Concurrent Load", authenticated: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			if !t.cfg.LoadCheck || t.cfg.LoadCheckRequests <= 0 {
+				Logger.Print(ctx, Constants.SymbolInfo+" --load-check not set, skipping concurrent load check"+Constants.TwoNewLines)
+				return Check.CheckResult{Name: "load", OK: true, Status: Check.StatusSkip, Detail: "load check disabled"}
+			}
+			return Check.GatewayUnderLoad(ctx, t.auth, t.serviceIP, Check.LoadBurst{Requests: t.cfg.LoadCheckRequests, MaxErrorPct: t.cfg.LoadCheckMaxErrorPct})
+		}},
+		{name: "backup", title: "Checking Backup Schedule", authenticated: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.BackupSchedule(ctx, t.auth, t.serviceIP, t.cfg.MaxBackupAge)
+		}},
+		{name: "list-buckets", title: "Checking Gateway Can List Buckets", authenticated: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			return Check.CheckListBuckets(ctx, t.auth, t.serviceIP)
+		}},
+		{name: "object-count", title: "Checking Object Count Trend", critical: true, authenticated: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			if t.cfg.StateFile == "" {
+				Logger.Print(ctx, Constants.SymbolWarn+" No --state-file given, skipping object count trend check"+Constants.TwoNewLines)
+				return Check.CheckResult{Name: "object-count", OK: true, Status: Check.StatusSkip, Detail: "no state file"}
+			}
+			return Check.ObjectCount(ctx, t.auth, t.serviceIP, t.state, t.cfg.MaxObjectDropPct)
+		}},
+	}
+}
+
+// apiChecks returns the checks that only talk to the gateway API, for runs without Kubernetes access.
+func apiChecks(all []check) []check {
+	scheduled := []check{}
+	for _, c := range all {
+		if !c.kubernetes {
+			scheduled = append(scheduled, c)
+		}
+	}
+	return scheduled
+}
+
+// selectChecks narrows all down to the checks named in only, when it is non-empty, minus those named in
+// skip, keeping their order. Names that aren't registered are rejected.
+func selectChecks(all []check, only, skip []string) ([]check, error) {
+	known := map[string]bool{}
+	names := []string{}
+	for _, c := range all {
+		known[c.name] = true
+		names = append(names, c.name)
+	}
+	for _, name := range append(append([]string{}, only...), skip...) {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown check '%s', known checks: %s", name, strings.Join(names, ", "))
+		}
+	}
+
+	scheduled := []check{}
+	for _, c := range all {
+		if len(only) > 0 && !slices.Contains(only, c.name) || slices.Contains(skip, c.name) {
+			continue
+		}
+		scheduled = append(scheduled, c)
+	}
+	return scheduled, nil
+}
+
+// needsAuthentication reports whether any of the checks calls the gateway API with a token.
+func needsAuthentication(scheduled []check) bool {
+	return slices.ContainsFunc(scheduled, func(c check) bool { return c.authenticated })
+}
+
+// listChecks prints the name and title of every registered check.
+func listChecks(w io.Writer, all []check) {
+	for _, c := range all {
+		fmt.Fprintf(w, "%-28s %s\n", c.name, c.title)
+	}
+}
+
+// runChecks runs the checks against the target with at most parallelism of them in flight and returns
+// their results in check order, numbering each header by its position among the scheduled checks.
+// Fatal checks run first, one at a time; when one fails the checks that depend on it are skipped, the
+// others still run and the run ends with a CheckError. With a parallelism of 1 the remaining checks run
+// serially, each header printed before the check starts; otherwise each check's log output is held back
+// and printed under its header in check order once every check has finished. Checks whose circuit is
+// open in the breaker are skipped. When the target's context is cancelled, checks still in flight or not
+// yet started are reported as cancelled and the run ends with an InterruptError.
+func runChecks(stdout io.Writer, scheduled []check, t *target, parallelism int, breaker *Utils.CircuitBreaker) ([]Check.CheckResult, error) {
+	results := make([]Check.CheckResult, len(scheduled))
+	printHeader := func(i int) {
+		fmt.Fprintf(stdout, "%s[%d/%d] %s %s%s%s%s", Constants.BoldGreen, i+1, len(scheduled), scheduled[i].title,
+			Constants.Reset, Constants.Newline, Constants.Differentiator, Constants.TwoNewLines)
+	}
+	interrupted := func() ([]Check.CheckResult, error) {
+		for i, c := range scheduled {
+			if results[i].Name == "" {
+				results[i] = cancelledResult(c)
+			}
+		}
+		return results, &Utils.InterruptError{Err: errors.New("run interrupted before every check finished")}
+	}
+
+	pending := []int{}
+	for i, c := range scheduled {
+		if !c.fatal {
+			pending = append(pending, i)
+		}
+	}
+	var failure error
+	for i, c := range scheduled {
+		if !c.fatal {
+			continue
+		}
+		printHeader(i)
+		results[i] = runCheck(t.ctx, c, t, breaker)
+		if t.ctx.Err() != nil {
+			return interrupted()
+		}
+		if !results[i].OK && failure == nil {
+			failure = &Utils.CheckError{Check: c.name, Err: errors.New(results[i].Detail)}
+			log.Printf(Constants.SymbolFail+" Fatal check '%s' failed, skipping the checks that depend on it", c.name)
+			pending = skipDependents(t.ctx, pending, scheduled, results, c)
+		}
+	}
+
+	if parallelism <= 1 {
+		for _, i := range pending {
+			if t.ctx.Err() != nil {
+				return interrupted()
+			}
+			printHeader(i)
+			results[i] = runCheck(t.ctx, scheduled[i], t, breaker)
+			if !results[i].OK && results[i].Status != Check.StatusCancelled {
+				logFailure(t.ctx, results[i])
+			}
+		}
+		if t.ctx.Err() != nil {
+			return interrupted()
+		}
+		return results, failure
+	}
+
+	// Each check logs into a buffer of its own, flushed under its header in check order once every check
+	// has finished, so concurrent checks don't interleave their output.
+	log.Printf("Running %d checks with parallelism %d", len(pending), parallelism)
+	buffers := make([]*Logger.Buffer, len(scheduled))
+	semaphore := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for _, i := range pending {
+		ctx, buffer := Logger.Buffered(t.ctx)
+		buffers[i] = buffer
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			select {
+			case semaphore <- struct{}{}:
+			case <-t.ctx.Done():
+				results[i] = cancelledResult(scheduled[i])
+				return
+			}
+			defer func() { <-semaphore }()
+			results[i] = runCheck(ctx, scheduled[i], t, breaker)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, i := range pending {
+		printHeader(i)
+		buffers[i].Flush()
+		if !results[i].OK && results[i].Status != Check.StatusCancelled {
+			logFailure(t.ctx, results[i])
+		}
+	}
+	if t.ctx.Err() != nil {
+		return interrupted()
+	}
+	return results, failure
+}
+
+// skipDependents reports the checks in pending of the same kind as the failed fatal check as skipped,
+// and returns the pending checks left to run.
+func skipDependents(ctx context.Context, pending []int, scheduled []check, results []Check.CheckResult, fatal check) []int {
+	remaining := []int{}
+	for _, i := range pending {
+		if scheduled[i].kubernetes != fatal.kubernetes {
+			remaining = append(remaining, i)
+			continue
+		}
+		results[i] = skippedResult(ctx, scheduled[i], fmt.Sprintf("fatal check '%s' failed", fatal.name))
+	}
+	return remaining
+}
+
+// runCheck runs a single check unless its circuit is open, in which case the check is reported as skipped
+// without touching the endpoint. A check still running when the target's context is cancelled is
+// abandoned and reported as cancelled, and one still running after --check-timeout fails as timed out.
+// The check logs through ctx, which is derived from the target's context.
+func runCheck(ctx context.Context, c check, t *target, breaker *Utils.CircuitBreaker) Check.CheckResult {
+	switch {
+	case !c.kubernetes && t.serviceIP == "":
+		return skippedResult(ctx, c, "the gateway address is unknown")
+	case c.authenticated && t.auth == nil:
+		return skippedResult(ctx, c, "no gateway token could be obtained")
+	}
+	if retryAt, open := breaker.Open(c.name); open {
+		return skippedResult(ctx, c, fmt.Sprintf("circuit open after repeated failures, retrying after %s", retryAt.Format(time.TimeOnly)))
+	}
+	cancel := context.CancelFunc(func() {})
+	if t.cfg.CheckTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, t.cfg.CheckTimeout)
+	}
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan Check.CheckResult, 1)
+	go func() { done <- c.run(ctx, t) }()
+	var result Check.CheckResult
+	select {
+	case result = <-done:
+		// Only a check that returned may change the state; one abandoned on timeout keeps running and
+		// must not race with the state being saved.
+		if result.State != nil && t.state != nil {
+			t.state.Merge(result.State)
+		}
+	case <-ctx.Done():
+	}
+	// A check that honours its context returns an error of its own on timeout, so the deadline decides
+	// the outcome rather than whichever detail the check produced.
+	switch {
+	case t.ctx.Err() != nil:
+		result = cancelledResult(c)
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		result = Check.CheckResult{
+			Name:   c.name,
+			Detail: fmt.Sprintf(Constants.SymbolFail+" %s timed out after %s", c.name, t.cfg.CheckTimeout),
+			Err:    ctx.Err(),
+		}
+	}
+	result.Duration = time.Since(start)
+	if result.Status == Check.StatusCancelled {
+		return result
+	}
+	result.Status = result.Outcome()
+	result.Critical = result.Critical || c.critical
+	breaker.Record(c.name, result.OK)
+	// Components are expected to fail while the cluster is upgrading, so they don't raise an alarm.
+	if t.upgrade != "" && !c.fatal && result.Status == Check.StatusFail {
+		result.OK = true
+		result.Status = Check.StatusWarn
+		result.Detail = "cluster is upgrading: " + result.Detail
+	}
+	return result
+}
+
+// logFailure logs a failed check's detail at ERROR, so it still shows with --quiet.
+func logFailure(ctx context.Context, result Check.CheckResult) {
+	Logger.Errorf(ctx, "%s", strings.TrimSpace(strings.TrimPrefix(result.Detail, Constants.SymbolFail)))
+}
+
+// skippedResult reports a check that couldn't run because something it depends on failed or its circuit
+// is open.
+func skippedResult(ctx context.Context, c check, reason string) Check.CheckResult {
+	Logger.Printf(ctx, Constants.SymbolInfo+" Skipping '%s': %s", c.name, reason)
+	return Check.CheckResult{Name: c.name, OK: true, Status: Check.StatusSkip, Detail: "skipped: " + reason}
+}
+
+// cancelledResult reports a check that didn't finish before the run was interrupted.
+func cancelledResult(c check) Check.CheckResult {
+	return Check.CheckResult{Name: c.name, Status: Check.StatusCancelled, Detail: "cancelled: the run was interrupted"}
+}
+
+// verdict decides whether a run leaves the cluster healthy. A run cut short by an error never does.
+// Otherwise up to failThreshold failing checks are tolerated; critical failures count toward the
+// threshold and, with criticalBypass, make the cluster unhealthy on their own. It also explains the
+// decision.
+func verdict(results []Check.CheckResult, err error, failThreshold int, criticalBypass bool) (bool, string) {
+	if err != nil {
+		return false, fmt.Sprintf("run incomplete (%s error)", Utils.ErrorCategory(err))
+	}
+	failed, critical := 0, 0
+	for _, result := range results {
+		if result.Outcome() != Check.StatusFail {
+			continue
+		}
+		failed++
+		if result.Critical {
+			critical++
+		}
+	}
+	reason := fmt.Sprintf("%d failing checks (%d critical), fail threshold %d", failed, critical, failThreshold)
+	if critical > 0 && criticalBypass {
+		return false, reason
+	}
+	return failed <= failThreshold, reason
+}
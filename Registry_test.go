@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	Check "Detective/Checks"
+	Logger "Detective/Logger"
+	Utils "Detective/Utils"
+)
+
+// captureLog sends the standard logger's output to a buffer for the rest of the test.
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	out, flags := log.Writer(), log.Flags()
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	t.Cleanup(func() {
+		log.SetOutput(out)
+		log.SetFlags(flags)
+	})
+	return &buf
+}
+
+func TestRunChecksRespectsParallelism(t *testing.T) {
+	captureLog(t)
+	const parallelism = 3
+	var inFlight, peak atomic.Int32
+	scheduled := []check{}
+	for range 10 {
+		scheduled = append(scheduled, check{name: "stub", kubernetes: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			current := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				seen := peak.Load()
+				if current <= seen || peak.CompareAndSwap(seen, current) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			return Check.CheckResult{Name: "stub", OK: true}
+		}})
+	}
+
+	if _, err := runChecks(io.Discard, scheduled, &target{ctx: context.Background()}, parallelism, nil); err != nil {
+		t.Fatalf("runChecks: %v", err)
+	}
+
+	if got := peak.Load(); got > parallelism {
+		t.Errorf("peak checks in flight = %d, want at most %d", got, parallelism)
+	} else if got < 2 {
+		t.Errorf("peak checks in flight = %d, want the checks to run concurrently", got)
+	}
+}
+
+func TestRunChecksPrintsOutputInCheckOrder(t *testing.T) {
+	logs := captureLog(t)
+	scheduled := []check{
+		{name: "slow", kubernetes: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			time.Sleep(50 * time.Millisecond)
+			Logger.Print(ctx, "slow check output")
+			return Check.CheckResult{Name: "slow", OK: true}
+		}},
+		{name: "fast", kubernetes: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			Logger.Print(ctx, "fast check output")
+			return Check.CheckResult{Name: "fast", OK: true}
+		}},
+	}
+
+	if _, err := runChecks(io.Discard, scheduled, &target{ctx: context.Background()}, 2, nil); err != nil {
+		t.Fatalf("runChecks: %v", err)
+	}
+
+	slow, fast := strings.Index(logs.String(), "slow check output"), strings.Index(logs.String(), "fast check output")
+	if slow < 0 || fast < 0 || slow > fast {
+		t.Errorf("expected the slow check's output before the fast one's, got:\n%s", logs)
+	}
+}
+
+func TestNeedsAuthentication(t *testing.T) {
+	tests := []struct {
+		name string
+		only []string
+		want bool
+	}{
+		{"kubernetes checks only", []string{"kubernetes", "pods", "pv"}, false},
+		{"gateway check selected", []string{"pods", "disk"}, true},
+		{"every check", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduled, err := selectChecks(checks(), tt.only, nil)
+			if err != nil {
+				t.Fatalf("selectChecks: %v", err)
+			}
+			if got := needsAuthentication(scheduled); got != tt.want {
+				t.Errorf("needsAuthentication = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunCheckAppliesStateOnlyWhenTheCheckReturns(t *testing.T) {
+	captureLog(t)
+	recordDisksets := func(delay time.Duration, count int64) check {
+		return check{name: "diskset-count", kubernetes: true, run: func(ctx context.Context, t *target) Check.CheckResult {
+			time.Sleep(delay)
+			return Check.CheckResult{Name: "diskset-count", OK: true, State: &Utils.State{Disksets: &count}}
+		}}
+	}
+	tgt := &target{ctx: context.Background(), cfg: Config{CheckTimeout: 50 * time.Millisecond}, state: &Utils.State{}}
+
+	if result := runCheck(tgt.ctx, recordDisksets(0, 4), tgt, nil); !result.OK {
+		t.Fatalf("check failed: %s", result.Detail)
+	}
+	if tgt.state.Disksets == nil || *tgt.state.Disksets != 4 {
+		t.Fatalf("state.Disksets = %v, want 4", tgt.state.Disksets)
+	}
+
+	if result := runCheck(tgt.ctx, recordDisksets(100*time.Millisecond, 3), tgt, nil); result.OK {
+		t.Fatal("OK = true, want the check to time out")
+	}
+	time.Sleep(150 * time.Millisecond)
+	if *tgt.state.Disksets != 4 {
+		t.Errorf("state.Disksets = %d after a timed out check, want 4", *tgt.state.Disksets)
+	}
+}
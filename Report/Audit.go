@@ -0,0 +1,52 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	Utils "Detective/Utils"
+)
+
+// auditReporter writes the complete run as plain text without colors, for archiving: when and where it
+// ran, every check's outcome and detail, the issues found and the verdict. Reports appended to the same
+// file are separated by their header line.
+type auditReporter struct{}
+
+func (auditReporter) Write(w io.Writer, report Report) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "=== Run %s ===\n", report.RunID)
+	fmt.Fprintf(&b, "Started:    %s\n", report.Started.Format(time.RFC3339))
+	fmt.Fprintf(&b, "Duration:   %s\n", report.Elapsed.Round(time.Millisecond))
+	fmt.Fprintf(&b, "Context:    %s\n", report.Context)
+	if report.APIServer != "" {
+		fmt.Fprintf(&b, "API server: %s\n", report.APIServer)
+	}
+	if report.Gateway != "" {
+		fmt.Fprintf(&b, "Gateway:    %s\n", report.Gateway)
+	}
+
+	b.WriteString("\nChecks:\n")
+	for _, result := range report.Results {
+		fmt.Fprintf(&b, "  %-9s %-30s %8s  %s\n", result.Outcome(), result.Name, result.Duration.Round(time.Millisecond), message(result.Detail))
+	}
+
+	if len(report.Issues) > 0 {
+		b.WriteString("\nIssues:\n")
+		for _, issue := range report.Issues {
+			b.WriteString("  - " + message(issue) + "\n")
+		}
+	}
+	if report.Err != nil {
+		fmt.Fprintf(&b, "\nRun incomplete (%s error): %s\n", Utils.ErrorCategory(report.Err), message(report.Err.Error()))
+	}
+
+	overall := "UNHEALTHY"
+	if report.Healthy {
+		overall = "HEALTHY"
+	}
+	fmt.Fprintf(&b, "\nOverall: %s (%s)\n%s\n\n", overall, report.Verdict, Coverage(report.Results))
+	_, err := fmt.Fprint(w, b.String())
+	return err
+}
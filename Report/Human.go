@@ -0,0 +1,43 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	Check "Detective/Checks"
+	Constants "Detective/Constants"
+)
+
+// humanReporter prints the list of issues found and the overall verdict, or a success message when there
+// are no issues.
+type humanReporter struct{}
+
+func (humanReporter) Write(w io.Writer, report Report) error {
+	var b strings.Builder
+	if len(report.Issues) > 0 {
+		b.WriteString(Constants.BoldRed + "Issues detected during the health check:" + Constants.Reset + Constants.Newline + Constants.Differentiator + Constants.TwoNewLines)
+		for _, issue := range report.Issues {
+			b.WriteString(Constants.FgRed + "- " + strings.TrimSpace(issue) + Constants.Reset + Constants.Newline)
+		}
+		if report.Healthy {
+			b.WriteString(Constants.Newline + Constants.BoldGreen + "Overall: HEALTHY (" + report.Verdict + ")" + Constants.Reset + Constants.TwoNewLines)
+		} else {
+			b.WriteString(Constants.Newline + Constants.BoldRed + "Overall: UNHEALTHY (" + report.Verdict + ")" + Constants.Reset + Constants.TwoNewLines)
+		}
+	} else {
+		b.WriteString(Constants.Newline + Constants.BoldGreen + "Overall check successful! Both the cluster and the Object Store application are healthy. " + Constants.Reset + Constants.Newline + Constants.Differentiator + Constants.TwoNewLines)
+	}
+	cancelled := []string{}
+	for _, result := range report.Results {
+		if result.Outcome() == Check.StatusCancelled {
+			cancelled = append(cancelled, result.Name)
+		}
+	}
+	if len(cancelled) > 0 {
+		b.WriteString(Constants.FgYellow + "Cancelled before finishing: " + strings.Join(cancelled, ", ") + Constants.Reset + Constants.Newline)
+	}
+	b.WriteString(Coverage(report.Results) + Constants.TwoNewLines)
+	_, err := fmt.Fprint(w, b.String())
+	return err
+}
@@ -0,0 +1,69 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	Utils "Detective/Utils"
+)
+
+type jsonCheck struct {
+	Name            string                 `json:"name"`
+	Status          string                 `json:"status"`
+	Detail          string                 `json:"detail,omitempty"`
+	DurationSeconds float64                `json:"duration_seconds"`
+	Data            map[string]interface{} `json:"data,omitempty"`
+}
+
+type jsonError struct {
+	Category string `json:"category"`
+	Message  string `json:"message"`
+}
+
+type jsonReport struct {
+	RunID           string      `json:"run_id"`
+	Context         string      `json:"context"`
+	APIServer       string      `json:"api_server,omitempty"`
+	Gateway         string      `json:"gateway,omitempty"`
+	Started         time.Time   `json:"started"`
+	DurationSeconds float64     `json:"duration_seconds"`
+	Healthy         bool        `json:"healthy"`
+	Verdict         string      `json:"verdict"`
+	Error           *jsonError  `json:"error,omitempty"`
+	Issues          []string    `json:"issues"`
+	Checks          []jsonCheck `json:"checks"`
+}
+
+// jsonReporter writes the report as a single JSON document for scripts and dashboards.
+type jsonReporter struct{}
+
+func (jsonReporter) Write(w io.Writer, report Report) error {
+	document := jsonReport{
+		RunID:           report.RunID,
+		Context:         report.Context,
+		APIServer:       report.APIServer,
+		Gateway:         report.Gateway,
+		Started:         report.Started,
+		DurationSeconds: report.Elapsed.Seconds(),
+		Healthy:         report.Healthy,
+		Verdict:         report.Verdict,
+		Issues:          append([]string{}, report.Issues...),
+		Checks:          []jsonCheck{},
+	}
+	if report.Err != nil {
+		document.Error = &jsonError{Category: Utils.ErrorCategory(report.Err), Message: message(report.Err.Error())}
+	}
+	for _, result := range report.Results {
+		document.Checks = append(document.Checks, jsonCheck{
+			Name:            result.Name,
+			Status:          string(result.Outcome()),
+			Detail:          message(result.Detail),
+			DurationSeconds: result.Duration.Seconds(),
+			Data:            result.Data,
+		})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
@@ -0,0 +1,79 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	Check "Detective/Checks"
+)
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+// junitReporter writes the report as a JUnit XML test suite, one test case per check, so CI systems
+// can display the health check like a test run. Warnings pass and carry their message as output.
+type junitReporter struct{}
+
+func (junitReporter) Write(w io.Writer, report Report) error {
+	suite := junitTestSuite{
+		Name:      "ostore-health-check." + report.Context,
+		Tests:     len(report.Results),
+		Time:      fmt.Sprintf("%.3f", report.Elapsed.Seconds()),
+		Timestamp: report.Started.UTC().Format("2006-01-02T15:04:05"),
+	}
+	for _, result := range report.Results {
+		testCase := junitTestCase{
+			Name:      result.Name,
+			ClassName: "ostore." + report.Context,
+			Time:      fmt.Sprintf("%.3f", result.Duration.Seconds()),
+		}
+		switch result.Outcome() {
+		case Check.StatusFail:
+			suite.Failures++
+			testCase.Failure = &junitFailure{Message: message(result.Detail)}
+		case Check.StatusSkip, Check.StatusCancelled:
+			suite.Skipped++
+			testCase.Skipped = &junitSkipped{Message: message(result.Detail)}
+		case Check.StatusWarn:
+			testCase.SystemOut = "warning: " + message(result.Detail)
+		}
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suite); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
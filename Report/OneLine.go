@@ -0,0 +1,43 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	Check "Detective/Checks"
+	Constants "Detective/Constants"
+)
+
+// OneLine formats a check result as a single scannable line, e.g. "PASS disk (120ms)" or
+// "FAIL diskset: Diskset 3 offline".
+func OneLine(result Check.CheckResult) string {
+	line := fmt.Sprintf("%-4s %s (%s)", result.Outcome(), result.Name, result.Duration.Round(time.Millisecond))
+	if result.Outcome() == Check.StatusPass {
+		return line
+	}
+	return line + ": " + message(result.Detail)
+}
+
+// message flattens a check's detail to one line without its leading status symbol.
+func message(detail string) string {
+	detail = strings.Join(strings.Fields(detail), " ")
+	for _, symbol := range []string{Constants.SymbolFail, Constants.SymbolWarn, Constants.SymbolInfo, Constants.SymbolOK} {
+		detail = strings.TrimSpace(strings.TrimPrefix(detail, symbol))
+	}
+	return detail
+}
+
+// oneLineReporter prints one line per check followed by the coverage summary.
+type oneLineReporter struct{}
+
+func (oneLineReporter) Write(w io.Writer, report Report) error {
+	var b strings.Builder
+	for _, result := range report.Results {
+		b.WriteString(OneLine(result) + Constants.Newline)
+	}
+	b.WriteString(Coverage(report.Results) + Constants.TwoNewLines)
+	_, err := fmt.Fprint(w, b.String())
+	return err
+}
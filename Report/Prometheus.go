@@ -0,0 +1,83 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	Check "Detective/Checks"
+)
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// TextfileName is the name of the metrics file for a kube context, so clusters sharing node_exporter's
+// textfile directory don't overwrite each other.
+func TextfileName(kubeContext string) string {
+	return "ostore_" + unsafeFileChars.ReplaceAllString(kubeContext, "_") + ".prom"
+}
+
+// prometheusReporter writes the check results as Prometheus metrics for node_exporter's textfile
+// collector.
+type prometheusReporter struct{}
+
+func (prometheusReporter) Write(w io.Writer, report Report) error {
+	var b strings.Builder
+	fmt.Fprintln(&b, "# HELP ostore_check Whether an ostore health check passed (1) or failed (0).")
+	fmt.Fprintln(&b, "# TYPE ostore_check gauge")
+	for _, result := range report.Results {
+		value := 0
+		if result.OK {
+			value = 1
+		}
+		fmt.Fprintf(&b, "ostore_check{context=%q,name=%q,status=%q} %d\n", report.Context, result.Name, result.Outcome(), value)
+	}
+	fmt.Fprintln(&b, "# HELP ostore_check_run_duration_seconds How long the last diagnostic run took.")
+	fmt.Fprintln(&b, "# TYPE ostore_check_run_duration_seconds gauge")
+	fmt.Fprintf(&b, "ostore_check_run_duration_seconds{context=%q,run_id=%q} %g\n", report.Context, report.RunID, report.Elapsed.Seconds())
+	fmt.Fprintln(&b, "# HELP ostore_check_last_run_timestamp_seconds When the last diagnostic run finished.")
+	fmt.Fprintln(&b, "# TYPE ostore_check_last_run_timestamp_seconds gauge")
+	fmt.Fprintf(&b, "ostore_check_last_run_timestamp_seconds{context=%q} %d\n", report.Context, time.Now().Unix())
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteMetrics writes the gauges served at /metrics by --serve: whether every check passed, how long
+// each took and the overall verdict of the run. Checks that were skipped are left out, so they can't be
+// mistaken for passing ones.
+func WriteMetrics(w io.Writer, report Report) error {
+	ran := []Check.CheckResult{}
+	for _, result := range report.Results {
+		if result.Outcome() != Check.StatusSkip {
+			ran = append(ran, result)
+		}
+	}
+	var b strings.Builder
+	fmt.Fprintln(&b, "# HELP ostore_check_up Whether an ostore health check passed (1) or failed (0) in the last run.")
+	fmt.Fprintln(&b, "# TYPE ostore_check_up gauge")
+	for _, result := range ran {
+		value := 0
+		if result.OK {
+			value = 1
+		}
+		fmt.Fprintf(&b, "ostore_check_up{check=%q} %d\n", result.Name, value)
+	}
+	fmt.Fprintln(&b, "# HELP ostore_check_duration_seconds How long an ostore health check took in the last run.")
+	fmt.Fprintln(&b, "# TYPE ostore_check_duration_seconds gauge")
+	for _, result := range ran {
+		fmt.Fprintf(&b, "ostore_check_duration_seconds{check=%q} %g\n", result.Name, result.Duration.Seconds())
+	}
+	healthy := 0
+	if report.Healthy {
+		healthy = 1
+	}
+	fmt.Fprintln(&b, "# HELP ostore_healthy Whether the last run found the cluster healthy (1) or not (0).")
+	fmt.Fprintln(&b, "# TYPE ostore_healthy gauge")
+	fmt.Fprintf(&b, "ostore_healthy %d\n", healthy)
+	fmt.Fprintln(&b, "# HELP ostore_check_last_run_timestamp_seconds When the last diagnostic run finished.")
+	fmt.Fprintln(&b, "# TYPE ostore_check_last_run_timestamp_seconds gauge")
+	fmt.Fprintf(&b, "ostore_check_last_run_timestamp_seconds %d\n", report.Started.Add(report.Elapsed).Unix())
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,37 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	Check "Detective/Checks"
+)
+
+func TestWriteMetricsOmitsSkippedChecks(t *testing.T) {
+	report := Report{Started: time.Now(), Healthy: true, Results: []Check.CheckResult{
+		{Name: "disk", OK: true, Duration: 1500 * time.Millisecond},
+		{Name: "nodes", Duration: 20 * time.Millisecond},
+		{Name: "backup", OK: true, Status: Check.StatusSkip},
+	}}
+	var b strings.Builder
+
+	if err := WriteMetrics(&b, report); err != nil {
+		t.Fatalf("WriteMetrics: %v", err)
+	}
+
+	metrics := b.String()
+	for _, want := range []string{
+		`ostore_check_up{check="disk"} 1`,
+		`ostore_check_up{check="nodes"} 0`,
+		`ostore_check_duration_seconds{check="disk"} 1.5`,
+		"ostore_healthy 1",
+	} {
+		if !strings.Contains(metrics, want) {
+			t.Errorf("metrics do not contain %q:\n%s", want, metrics)
+		}
+	}
+	if strings.Contains(metrics, `check="backup"`) {
+		t.Errorf("metrics contain the skipped check:\n%s", metrics)
+	}
+}
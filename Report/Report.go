@@ -0,0 +1,142 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	Check "Detective/Checks"
+)
+
+// Report is the outcome of one diagnostic run. Every output destination renders the same Report.
+type Report struct {
+	RunID     string
+	Context   string
+	APIServer string
+	Gateway   string
+	Started   time.Time
+	Elapsed   time.Duration
+	Results   []Check.CheckResult
+	// Issues lists every problem found during the run, including those raised outside the checks such
+	// as a failed RBAC preflight.
+	Issues []string
+	// Healthy is the overall decision on the cluster's health, explained by Verdict.
+	Healthy bool
+	Verdict string
+	// Err is the setup, discovery, authentication or fatal check error that cut the run short, if any.
+	Err error
+}
+
+// Reporter renders a Report in one output format.
+type Reporter interface {
+	Write(w io.Writer, report Report) error
+}
+
+// reporters maps every --output format to its Reporter. "text" is kept as an alias of "human".
+var reporters = map[string]Reporter{
+	"audit":               auditReporter{},
+	"human":               humanReporter{},
+	"text":                humanReporter{},
+	"oneline":             oneLineReporter{},
+	"json":                jsonReporter{},
+	"junit":               junitReporter{},
+	"prometheus-textfile": prometheusReporter{},
+}
+
+// Formats returns the known output formats in alphabetical order.
+func Formats() []string {
+	formats := make([]string, 0, len(reporters))
+	for format := range reporters {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// Output is one --output destination: a format and the file it is written to, or stdout when Path is
+// empty. Append adds the report to the end of the file instead of replacing it.
+type Output struct {
+	Format   string
+	Path     string
+	Reporter Reporter
+	Append   bool
+}
+
+// ParseOutput parses an --output value of the form "format" or "format:path", e.g. "human" or
+// "junit:results.xml".
+func ParseOutput(spec string) (Output, error) {
+	format, path, _ := strings.Cut(spec, ":")
+	reporter, found := reporters[format]
+	if !found {
+		return Output{}, fmt.Errorf("unknown output format '%s', expected one of %s", format, strings.Join(Formats(), ", "))
+	}
+	return Output{Format: format, Path: path, Reporter: reporter}, nil
+}
+
+// Human reports whether the output is the human-readable format.
+func (o Output) Human() bool {
+	return o.Format == "human" || o.Format == "text"
+}
+
+// WriteFile writes a report file at path through write. The file is written to a temporary sibling and
+// renamed into place so a reader such as node_exporter never sees a partial file.
+func WriteFile(path string, write func(w io.Writer) error) error {
+	// The temporary name must not keep the file's extension, since collectors pick files up by it.
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary report file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write report file '%s': %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write report file '%s': %w", path, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to set report file permissions: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace report file '%s': %w", path, err)
+	}
+	return nil
+}
+
+// AppendFile appends a report to the file at path through write, creating the file when it doesn't
+// exist, so repeated runs build up a history.
+func AppendFile(path string, write func(w io.Writer) error) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open report file '%s': %w", path, err)
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write report file '%s': %w", path, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write report file '%s': %w", path, err)
+	}
+	return nil
+}
+
+// Coverage summarizes how many checks ran and how each of them ended, so a skipped check is never
+// mistaken for a pass.
+func Coverage(results []Check.CheckResult) string {
+	counts := map[Check.Status]int{}
+	for _, result := range results {
+		counts[result.Outcome()]++
+	}
+	ran := len(results) - counts[Check.StatusSkip] - counts[Check.StatusCancelled]
+	coverage := fmt.Sprintf("Coverage: %d of %d checks ran: %d passed, %d failed, %d warned, %d skipped",
+		ran, len(results), counts[Check.StatusPass], counts[Check.StatusFail], counts[Check.StatusWarn], counts[Check.StatusSkip])
+	if counts[Check.StatusCancelled] > 0 {
+		coverage += fmt.Sprintf(", %d cancelled", counts[Check.StatusCancelled])
+	}
+	return coverage
+}
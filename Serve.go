@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	Constants "Detective/Constants"
+	Report "Detective/Report"
+)
+
+// metricsHandler serves the outcome of the last completed run as Prometheus gauges. Until the first
+// run completes it serves no metrics.
+type metricsHandler struct {
+	mu     sync.RWMutex
+	report *Report.Report
+}
+
+func (h *metricsHandler) update(report Report.Report) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.report = &report
+}
+
+func (h *metricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.RLock()
+	report := h.report
+	h.mu.RUnlock()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if report == nil {
+		return
+	}
+	if err := Report.WriteMetrics(w, *report); err != nil {
+		log.Printf(Constants.SymbolFail+" Unable to write metrics: %v", err)
+	}
+}
+
+// serve calls runOnce every --interval and publishes the last completed run at /metrics on --listen,
+// until SIGINT or SIGTERM, then returns exitInterrupted. A run cut short by the signal is not published.
+func serve(cfg Config, runOnce func(ctx context.Context) Report.Report) int {
+	listener, err := net.Listen("tcp", cfg.Listen)
+	if err != nil {
+		fatalf("Error listening on %s: %v", cfg.Listen, err)
+	}
+	metrics := &metricsHandler{}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics)
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf(Constants.SymbolFail+" Metrics server stopped: %v", err)
+		}
+	}()
+	log.Printf("Serving metrics at http://%s/metrics", listener.Addr())
+
+	ctx, stop := interruptContext()
+	defer stop()
+	for ctx.Err() == nil {
+		report := runOnce(ctx)
+		if ctx.Err() != nil {
+			break
+		}
+		metrics.update(report)
+		log.Printf("Next run in %s", cfg.WatchInterval)
+		select {
+		case <-time.After(cfg.WatchInterval):
+		case <-ctx.Done():
+		}
+	}
+
+	shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdown); err != nil {
+		log.Printf(Constants.SymbolWarn+" Metrics server did not shut down cleanly: %v", err)
+	}
+	return exitInterrupted
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// resetSignals reset the watch session's duration statistics.
+var resetSignals = []os.Signal{syscall.SIGUSR1}
@@ -0,0 +1,6 @@
+package main
+
+import "os"
+
+// resetSignals is empty on Windows, which has no SIGUSR1.
+var resetSignals = []os.Signal{}
@@ -0,0 +1,177 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	Logger "Detective/Logger"
+)
+
+// Authenticator supplies the token sent with every gateway API request. Token is called once per run
+// and again whenever the gateway rejects a request with 401, so an implementation may return a fresh
+// token each time.
+type Authenticator interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// headerAuthenticator is implemented by authenticators whose token goes in a header other than
+// x-rakuten-token.
+type headerAuthenticator interface {
+	header(token string) (name, value string)
+}
+
+// PasswordAuthenticator logs in to the gateway with a username and password.
+type PasswordAuthenticator struct {
+	ServiceIP string
+	Username  string
+	Password  string
+}
+
+func (a PasswordAuthenticator) Token(ctx context.Context) (string, error) {
+	if a.Username == "" || a.Password == "" {
+		return "", fmt.Errorf("no gateway credentials: both a username and a password are required")
+	}
+	credentials, err := json.Marshal(map[string]string{"username": a.Username, "password": a.Password})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode credentials: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", EndpointURL(a.ServiceIP, "login"), strings.NewReader(string(credentials)))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-rakuten-internal", "user")
+	ApplyUserAgent(req)
+
+	resp, err := Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return "", &AuthError{Err: fmt.Errorf("gateway rejected the login: %s. Body: %s", resp.Status, strings.TrimSpace(string(body)))}
+	}
+	token := resp.Header.Get("X-Rakuten-Token")
+	if token != "" {
+		Logger.Debugf(ctx, "Login token supplied by the X-Rakuten-Token header")
+		return token, nil
+	}
+
+	// Some gateway versions return the token in the JSON body instead of the header.
+	var body struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err == nil && body.Token != "" {
+		Logger.Debugf(ctx, "Login token supplied by the 'token' field of the response body")
+		return body.Token, nil
+	}
+	return "", fmt.Errorf("header 'X-Rakuten-Token' not found in the response and the body has no 'token' field")
+}
+
+// StaticTokenAuthenticator uses a token issued out of band, sent as x-rakuten-token.
+type StaticTokenAuthenticator struct {
+	Value string
+}
+
+func (a StaticTokenAuthenticator) Token(ctx context.Context) (string, error) {
+	if a.Value == "" {
+		return "", fmt.Errorf("static token is empty")
+	}
+	return a.Value, nil
+}
+
+// TokenFileAuthenticator reads a short-lived token, such as an OIDC or projected service account token,
+// from a file on every call so a rotated token is always picked up.
+type TokenFileAuthenticator struct {
+	Path string
+}
+
+func (a TokenFileAuthenticator) Token(ctx context.Context) (string, error) {
+	return ReadTokenFile(a.Path)
+}
+
+// BearerAuthenticator sends a token as "Authorization: Bearer", for gateways fronted by a proxy that
+// authenticates requests itself.
+type BearerAuthenticator struct {
+	Value string
+}
+
+func (a BearerAuthenticator) Token(ctx context.Context) (string, error) {
+	if a.Value == "" {
+		return "", fmt.Errorf("bearer token is empty")
+	}
+	return a.Value, nil
+}
+
+func (a BearerAuthenticator) header(token string) (string, string) {
+	return "Authorization", "Bearer " + token
+}
+
+// CachedAuthenticator asks the Authenticator it wraps for a token once and hands out the same token
+// until the gateway rejects it, so a run logs in once however many requests its checks make.
+type CachedAuthenticator struct {
+	Authenticator
+	mu    sync.Mutex
+	token string
+}
+
+func NewCachedAuthenticator(a Authenticator) *CachedAuthenticator {
+	return &CachedAuthenticator{Authenticator: a}
+}
+
+func (c *CachedAuthenticator) Token(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token == "" {
+		token, err := c.Authenticator.Token(ctx)
+		if err != nil {
+			return "", err
+		}
+		c.token = token
+	}
+	return c.token, nil
+}
+
+// refresh replaces a token the gateway rejected with a fresh one. When another request already
+// replaced it, that token is returned instead of logging in again.
+func (c *CachedAuthenticator) refresh(ctx context.Context, rejected string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != rejected {
+		return c.token, nil
+	}
+	token, err := c.Authenticator.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.token = token
+	return token, nil
+}
+
+func (c *CachedAuthenticator) header(token string) (string, string) {
+	return authHeader(c.Authenticator, token)
+}
+
+// authHeader returns the header carrying a token issued by a.
+func authHeader(a Authenticator, token string) (name, value string) {
+	if h, ok := a.(headerAuthenticator); ok {
+		return h.header(token)
+	}
+	return "x-rakuten-token", token
+}
+
+// freshToken asks a for a token to replace the rejected one.
+func freshToken(ctx context.Context, a Authenticator, rejected string) (string, error) {
+	if c, ok := a.(*CachedAuthenticator); ok {
+		return c.refresh(ctx, rejected)
+	}
+	return a.Token(ctx)
+}
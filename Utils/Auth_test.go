@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// gatewayServer starts a TLS server with handler and points the gateway ports at it, returning the
+// address to pass as the service IP.
+func gatewayServer(t *testing.T, handler http.HandlerFunc) string {
+	t.Helper()
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, _ := strconv.Atoi(port)
+	if err := SetPorts(p, p); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { SetPorts(9001, 9000) })
+	return host
+}
+
+func TestPasswordAuthenticatorRejectedLogin(t *testing.T) {
+	serviceIP := gatewayServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid credentials", http.StatusForbidden)
+	})
+
+	_, err := PasswordAuthenticator{ServiceIP: serviceIP, Username: "admin", Password: "wrong"}.Token(context.Background())
+
+	var authErr *AuthError
+	if !errors.As(err, &authErr) {
+		t.Fatalf("Token error = %v, want an *AuthError", err)
+	}
+}
+
+// countingAuthenticator hands out "token-N" on its Nth call.
+type countingAuthenticator struct {
+	calls int
+}
+
+func (a *countingAuthenticator) Token(ctx context.Context) (string, error) {
+	a.calls++
+	return "token-" + strconv.Itoa(a.calls), nil
+}
+
+func TestDoAuthenticatedRefreshesRejectedToken(t *testing.T) {
+	serviceIP := gatewayServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-rakuten-token") != "token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+	counting := &countingAuthenticator{}
+	auth := NewCachedAuthenticator(counting)
+
+	for range 2 {
+		req, _ := http.NewRequest("GET", EndpointURL(serviceIP, "version"), nil)
+		resp, err := DoAuthenticated(req, auth)
+		if err != nil {
+			t.Fatalf("DoAuthenticated: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("status = %d, want 200", resp.StatusCode)
+		}
+	}
+	if counting.calls != 2 {
+		t.Errorf("logged in %d times, want 2: once for the run and once after the 401", counting.calls)
+	}
+}
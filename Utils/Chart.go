@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// ChartSelector picks the Helm release to diagnose by its chart: by name alone, matching any version,
+// or by name and a version constraint.
+type ChartSelector struct {
+	Name       string
+	Constraint *semver.Constraints
+	spec       string
+}
+
+// legacyChartSpec matches the "name-version" form, e.g. "ostore-1.5.0", which pins an exact version.
+var legacyChartSpec = regexp.MustCompile(`^(.+)-(v?\d+\.\d+\.\d+\S*)$`)
+
+// ParseChartSelector parses a --chart value: "ostore" for any version, "ostore@>=1.5.0" or
+// "ostore@~1.5" for versions satisfying a semver constraint, or "ostore-1.5.0" for that exact version.
+func ParseChartSelector(spec string) (ChartSelector, error) {
+	name, constraint, versioned := strings.Cut(spec, "@")
+	if !versioned {
+		if match := legacyChartSpec.FindStringSubmatch(spec); match != nil {
+			name, constraint, versioned = match[1], match[2], true
+		}
+	}
+	if name == "" {
+		return ChartSelector{}, fmt.Errorf("chart '%s' has no name", spec)
+	}
+	selector := ChartSelector{Name: name, spec: spec}
+	if versioned {
+		parsed, err := semver.NewConstraint(constraint)
+		if err != nil {
+			return ChartSelector{}, fmt.Errorf("invalid version constraint in chart '%s': %w", spec, err)
+		}
+		selector.Constraint = parsed
+	}
+	return selector, nil
+}
+
+// Matches reports whether a chart with the given name and version is selected. A version that isn't
+// valid semver only matches a selector without a constraint.
+func (s ChartSelector) Matches(name, version string) bool {
+	if name != s.Name {
+		return false
+	}
+	if s.Constraint == nil {
+		return true
+	}
+	parsed, err := semver.NewVersion(version)
+	return err == nil && s.Constraint.Check(parsed)
+}
+
+func (s ChartSelector) String() string {
+	return s.spec
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// CircuitBreaker tracks consecutive failures per check. Once a check fails
+// threshold times in a row its circuit opens and the check is skipped until
+// the cool-down elapses; the next attempt either closes the circuit on
+// success or reopens it on failure. A nil CircuitBreaker never opens.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  map[string]int
+	openUntil map[string]time.Time
+}
+
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		failures:  map[string]int{},
+		openUntil: map[string]time.Time{},
+	}
+}
+
+// Open reports whether the circuit for name is open and, if so, when the
+// check will be attempted again.
+func (b *CircuitBreaker) Open(name string) (time.Time, bool) {
+	if b == nil {
+		return time.Time{}, false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	until, found := b.openUntil[name]
+	return until, found && time.Now().Before(until)
+}
+
+// Record registers the outcome of an attempt of the check.
+func (b *CircuitBreaker) Record(name string, ok bool) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if ok {
+		delete(b.failures, name)
+		delete(b.openUntil, name)
+		return
+	}
+	b.failures[name]++
+	if b.threshold > 0 && b.failures[name] >= b.threshold {
+		b.openUntil[name] = time.Now().Add(b.cooldown)
+	}
+}
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+)
+
+// DecodeError reports a gateway response that could not be decoded into the type a check expects.
+type DecodeError struct {
+	Endpoint string
+	Err      error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("failed to decode response from '%s': %v", e.Endpoint, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// StatusError reports a gateway response with a non-2xx status.
+type StatusError struct {
+	Endpoint   string
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("received non-successful HTTP status: %s. Body: %s", e.Status, e.Body)
+}
+
+// GetAndDecode fetches url from the gateway authenticated by auth and unmarshals the response body into
+// T, so checks work with typed values instead of asserting their way through an interface{}. A non-2xx
+// answer is reported as a *StatusError, and a body that doesn't fit T is reported as a *DecodeError
+// naming the endpoint.
+func GetAndDecode[T any](ctx context.Context, client *http.Client, url string, auth Authenticator) (T, error) {
+	var result T
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return result, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-rakuten-internal", "user")
+
+	resp, err := doAuthenticated(client, req, auth)
+	if err != nil {
+		return result, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return result, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return result, &StatusError{Endpoint: endpointName(url), StatusCode: resp.StatusCode, Status: resp.Status, Body: string(bodyBytes)}
+	}
+
+	if err := json.Unmarshal(bodyBytes, &result); err != nil {
+		return result, &DecodeError{Endpoint: endpointName(url), Err: err}
+	}
+	return result, nil
+}
+
+// endpointName returns the path of url, which identifies the endpoint in error messages.
+func endpointName(url string) string {
+	if u, err := neturl.Parse(url); err == nil && u.Path != "" {
+		return u.Path
+	}
+	return url
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// endpointPaths maps each gateway API endpoint the checks use to its path.
+var endpointPaths = map[string]string{
+	"login":          "/user",
+	"node":           "/node",
+	"replication":    "/cluster_replication_config",
+	"version":        "/version",
+	"diskset":        "/diskset?action=list",
+	"disk":           "/disk",
+	"ldap":           "/idp?idp=ldap",
+	"oidc":           "/idp?idp=oidc",
+	"saml":           "/idp?idp=saml",
+	"cluster_health": "/cluster_health",
+	"bucket":         "/bucket",
+	"backup":         "/backup",
+	"listeners":      "/listener_config",
+}
+
+// The ports the gateway serves its S3 data path, which also carries the replication config, and its
+// admin API on.
+var (
+	gatewayPort = "9000"
+	adminPort   = "9001"
+)
+
+// endpointPorts maps endpoints that aren't served on the admin port.
+var endpointPorts = map[string]*string{
+	"replication": &gatewayPort,
+}
+
+// GatewayPort returns the port of the gateway's S3 data path.
+func GatewayPort() string {
+	return gatewayPort
+}
+
+// AdminPort returns the port of the gateway's admin API.
+func AdminPort() string {
+	return adminPort
+}
+
+// SetPorts replaces the admin and gateway ports, for gateways exposed on non-default ports.
+func SetPorts(admin, gateway int) error {
+	for _, port := range []int{admin, gateway} {
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("invalid port %d, must be between 1 and 65535", port)
+		}
+	}
+	adminPort, gatewayPort = strconv.Itoa(admin), strconv.Itoa(gateway)
+	return nil
+}
+
+// EndpointURL builds the URL of a named gateway endpoint on serviceIP.
+func EndpointURL(serviceIP, name string) string {
+	port := adminPort
+	if override, found := endpointPorts[name]; found {
+		port = *override
+	}
+	return "https://" + net.JoinHostPort(serviceIP, port) + endpointPaths[name]
+}
+
+// SetEndpointOverrides replaces the path of the named endpoints, for gateways
+// that relocated part of their API. Unknown endpoint names are rejected.
+func SetEndpointOverrides(overrides map[string]string) error {
+	for name, path := range overrides {
+		if _, found := endpointPaths[name]; !found {
+			return fmt.Errorf("unknown endpoint '%s' in override, known endpoints: %s", name, strings.Join(EndpointNames(), ", "))
+		}
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		endpointPaths[name] = path
+	}
+	return nil
+}
+
+// EndpointNames returns the names of all known gateway endpoints, sorted.
+func EndpointNames() []string {
+	names := make([]string, 0, len(endpointPaths))
+	for name := range endpointPaths {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,71 @@
+package utils
+
+import "errors"
+
+// The error types below categorize why a run went wrong, so the top-level handler and structured
+// output can tell "couldn't reach the cluster" apart from "the cluster is unhealthy".
+
+// SetupError reports that the run couldn't be set up locally, e.g. an unreadable kubeconfig or state
+// file.
+type SetupError struct {
+	Err error
+}
+
+func (e *SetupError) Error() string { return e.Err.Error() }
+func (e *SetupError) Unwrap() error { return e.Err }
+
+// DiscoveryError reports that the ostore Helm release or its gateway couldn't be found in the cluster.
+type DiscoveryError struct {
+	Err error
+}
+
+func (e *DiscoveryError) Error() string { return e.Err.Error() }
+func (e *DiscoveryError) Unwrap() error { return e.Err }
+
+// AuthError reports that no token could be obtained for the gateway API.
+type AuthError struct {
+	Err error
+}
+
+func (e *AuthError) Error() string { return e.Err.Error() }
+func (e *AuthError) Unwrap() error { return e.Err }
+
+// CheckError reports that a check failed badly enough to abort the run.
+type CheckError struct {
+	Check string
+	Err   error
+}
+
+func (e *CheckError) Error() string { return e.Err.Error() }
+func (e *CheckError) Unwrap() error { return e.Err }
+
+// InterruptError reports that the run was interrupted, e.g. by SIGINT, before every check finished.
+type InterruptError struct {
+	Err error
+}
+
+func (e *InterruptError) Error() string { return e.Err.Error() }
+func (e *InterruptError) Unwrap() error { return e.Err }
+
+// ErrorCategory names the category of err: "setup", "discovery", "auth", "check" or "interrupt", or ""
+// when err carries none of the types above.
+func ErrorCategory(err error) string {
+	var setupErr *SetupError
+	var discoveryErr *DiscoveryError
+	var authErr *AuthError
+	var checkErr *CheckError
+	var interruptErr *InterruptError
+	switch {
+	case errors.As(err, &setupErr):
+		return "setup"
+	case errors.As(err, &discoveryErr):
+		return "discovery"
+	case errors.As(err, &authErr):
+		return "auth"
+	case errors.As(err, &checkErr):
+		return "check"
+	case errors.As(err, &interruptErr):
+		return "interrupt"
+	}
+	return ""
+}
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// fieldOverrides maps "endpoint.field" to the JSON key the gateway actually
+// uses, e.g. "disk.status_str" -> "statusStr". Fields without an override keep
+// their default name.
+var fieldOverrides = map[string]string{}
+
+// Field returns the JSON key to read for the named field of an endpoint's
+// response, honoring any configured override.
+func Field(endpoint, name string) string {
+	if override, found := fieldOverrides[endpoint+"."+name]; found {
+		return override
+	}
+	return name
+}
+
+// LoadFieldOverrides reads a JSON object mapping "endpoint.field" to the key
+// used by the gateway and installs it for Field.
+func LoadFieldOverrides(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read field map '%s': %w", path, err)
+	}
+	overrides := map[string]string{}
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return fmt.Errorf("failed to parse field map '%s': %w", path, err)
+	}
+	fieldOverrides = overrides
+	return nil
+}
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// KubeconfigSource is where the kubeconfig comes from: a file on disk, the raw bytes when it was
+// handed over on stdin so it is never written to the filesystem, or the pod's service account when
+// running inside the cluster.
+type KubeconfigSource struct {
+	Path      string
+	Data      []byte
+	InCluster bool
+}
+
+// inClusterContext is the context name reported for an in-cluster run, which has no kubeconfig.
+const inClusterContext = "in-cluster"
+
+// RunningInCluster reports whether the process runs in a pod with a service account it can use to
+// reach the API server.
+func RunningInCluster() bool {
+	_, err := rest.InClusterConfig()
+	return err == nil
+}
+
+// Load parses the whole kubeconfig, e.g. to enumerate its contexts.
+func (s KubeconfigSource) Load() (*clientcmdapi.Config, error) {
+	if s.InCluster {
+		return nil, fmt.Errorf("no kubeconfig when running in-cluster")
+	}
+	if s.Data != nil {
+		return clientcmd.Load(s.Data)
+	}
+	return clientcmd.LoadFromFile(s.Path)
+}
+
+// clientConfig returns the client config for kubeContext, or for the current context when it is empty.
+func (s KubeconfigSource) clientConfig(kubeContext string) (clientcmd.ClientConfig, error) {
+	if s.Data == nil {
+		return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+			&clientcmd.ClientConfigLoadingRules{ExplicitPath: s.Path},
+			&clientcmd.ConfigOverrides{CurrentContext: kubeContext},
+		), nil
+	}
+	raw, err := clientcmd.Load(s.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+	return clientcmd.NewNonInteractiveClientConfig(*raw, kubeContext, &clientcmd.ConfigOverrides{}, nil), nil
+}
+
+// restClientGetter hands Helm an already built REST config, since Helm's own config flags can only
+// read a kubeconfig from disk and so work neither with one from stdin nor in-cluster.
+type restClientGetter struct {
+	config *rest.Config
+}
+
+func (g restClientGetter) ToRESTConfig() (*rest.Config, error) {
+	return rest.CopyConfig(g.config), nil
+}
+
+func (g restClientGetter) ToDiscoveryClient() (discovery.CachedDiscoveryInterface, error) {
+	config, err := g.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return memory.NewMemCacheClient(client), nil
+}
+
+func (g restClientGetter) ToRESTMapper() (meta.RESTMapper, error) {
+	client, err := g.ToDiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+	return restmapper.NewDeferredDiscoveryRESTMapper(client), nil
+}
+
+// ToRawKubeConfigLoader is only used by Helm for its default namespace, which doesn't matter as
+// releases are listed across all namespaces.
+func (g restClientGetter) ToRawKubeConfigLoader() clientcmd.ClientConfig {
+	return clientcmd.NewDefaultClientConfig(*clientcmdapi.NewConfig(), &clientcmd.ConfigOverrides{
+		Context: clientcmdapi.Context{Namespace: metav1.NamespaceDefault},
+	})
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Int64 extracts an integer from a decoded JSON value, accepting both numbers and numeric strings such
+// as "42", since some gateway versions quote their numeric fields.
+func Int64(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, false
+		}
+		return int64(v), true
+	case json.Number:
+		n, err := v.Int64()
+		return n, err == nil
+	case string:
+		n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// ID formats an identifier from a decoded JSON value for messages and keys. Numeric IDs are printed as
+// integers whether they arrived as numbers or strings, instead of in float notation such as 1e+06.
+func ID(value interface{}) string {
+	if n, ok := Int64(value); ok {
+		return strconv.FormatInt(n, 10)
+	}
+	if value == nil {
+		return "unknown"
+	}
+	return fmt.Sprint(value)
+}
+
+// Bytes formats a byte count with a binary unit, e.g. "1.5 TiB".
+func Bytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value, exponent := float64(n), 0
+	for value >= unit && exponent < 6 {
+		value /= unit
+		exponent++
+	}
+	return fmt.Sprintf("%.1f %ciB", value, "KMGTPE"[exponent-1])
+}
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestInt64(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  int64
+		ok    bool
+	}{
+		{"float64", float64(42), 42, true},
+		{"fractional float64", 42.5, 0, false},
+		{"json.Number", json.Number("42"), 42, true},
+		{"large json.Number", json.Number("9007199254740993"), 9007199254740993, true},
+		{"string", "42", 42, true},
+		{"padded string", " 42 ", 42, true},
+		{"large string", "9007199254740993", 9007199254740993, true},
+		{"non-numeric string", "disk-1", 0, false},
+		{"missing", nil, 0, false},
+		{"bool", true, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Int64(tt.value)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("Int64(%#v) = %d, %v, want %d, %v", tt.value, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestID(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"float64", float64(1000000), "1000000"},
+		{"json.Number", json.Number("42"), "42"},
+		{"string", "42", "42"},
+		{"non-numeric string", "ds-a", "ds-a"},
+		{"missing", nil, "unknown"},
+		// 2^53+1 can't be represented as a float64 and rounds down; only the json.Number and string
+		// forms keep every digit.
+		{"large float64", float64(9007199254740993), "9007199254740992"},
+		{"large json.Number", json.Number("9007199254740993"), "9007199254740993"},
+		{"large string", "9007199254740993", "9007199254740993"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ID(tt.value); got != tt.want {
+				t.Errorf("ID(%#v) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var ipv4Pattern = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+
+// Redactor replaces IP addresses and registered hostnames with stable
+// placeholders such as "gateway-1", so reports can be shared without leaking
+// internal endpoints. The same value always maps to the same placeholder
+// within a run.
+type Redactor struct {
+	mu           sync.Mutex
+	placeholders map[string]string
+	counters     map[string]int
+	// pattern matches any registered value, longest first. It is rebuilt
+	// when a value is added.
+	pattern *regexp.Regexp
+}
+
+func NewRedactor() *Redactor {
+	return &Redactor{
+		placeholders: map[string]string{},
+		counters:     map[string]int{},
+	}
+}
+
+// Register assigns the next placeholder of the given kind to value and
+// returns it. A value that was already seen keeps its existing placeholder.
+func (r *Redactor) Register(value, kind string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.register(value, kind)
+}
+
+func (r *Redactor) register(value, kind string) string {
+	if value == "" {
+		return ""
+	}
+	if placeholder, found := r.placeholders[value]; found {
+		return placeholder
+	}
+	r.counters[kind]++
+	placeholder := fmt.Sprintf("%s-%d", kind, r.counters[kind])
+	r.placeholders[value] = placeholder
+	r.pattern = nil
+	return placeholder
+}
+
+// Alias makes value redact to an existing placeholder, e.g. so every address
+// of a node shares the node's placeholder.
+func (r *Redactor) Alias(value, placeholder string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if value != "" {
+		r.placeholders[value] = placeholder
+		r.pattern = nil
+	}
+}
+
+// Redact replaces every registered value and any IPv4 address in text.
+// Unregistered addresses are assigned a "host" placeholder on first sight.
+func (r *Redactor) Redact(text string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	text = ipv4Pattern.ReplaceAllStringFunc(text, func(ip string) string {
+		return r.register(ip, "host")
+	})
+
+	if len(r.placeholders) == 0 {
+		return text
+	}
+	if r.pattern == nil {
+		// Try longer values first so a hostname is never partially replaced
+		// by a registered value it contains.
+		values := make([]string, 0, len(r.placeholders))
+		for value := range r.placeholders {
+			values = append(values, value)
+		}
+		sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+		for i, value := range values {
+			values[i] = regexp.QuoteMeta(value)
+		}
+		r.pattern = regexp.MustCompile(strings.Join(values, "|"))
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, match := range r.pattern.FindAllStringIndex(text, -1) {
+		start, end := match[0], match[1]
+		if start > 0 && nameChar(text[start-1]) || end < len(text) && nameChar(text[end]) {
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(r.placeholders[text[start:end]])
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// nameChar reports whether c can be part of a node, pod or host label, so a
+// registered value is only replaced where it stands as a whole name: a node
+// called "master" leaves "yb-master" alone.
+func nameChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_'
+}
+
+// Writer wraps w so that everything written through it is redacted.
+func (r *Redactor) Writer(w io.Writer) io.Writer {
+	return redactingWriter{redactor: r, out: w}
+}
+
+type redactingWriter struct {
+	redactor *Redactor
+	out      io.Writer
+}
+
+func (w redactingWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(w.out, w.redactor.Redact(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,27 @@
+package utils
+
+import "testing"
+
+func TestRedactMatchesWholeNames(t *testing.T) {
+	r := NewRedactor()
+	r.Register("master", "node")
+	r.Register("ostore-gateway-0", "pod")
+	r.Register("ostore-gateway", "service")
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"node master is ready", "node node-1 is ready"},
+		{"yb-master 'yb-master-0' is healthy", "yb-master 'yb-master-0' is healthy"},
+		{"masters and remastered", "masters and remastered"},
+		{"pod ostore-gateway-0 behind ostore-gateway", "pod pod-1 behind service-1"},
+		{"master.example.com", "node-1.example.com"},
+		{"gateway at 10.1.2.3:9001", "gateway at host-1:9001"},
+	}
+	for _, tt := range tests {
+		if got := r.Redact(tt.text); got != tt.want {
+			t.Errorf("Redact(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	Logger "Detective/Logger"
+)
+
+// retryAttempts and retryBaseDelay bound how gateway requests are retried on transient failures, e.g.
+// while the gateway pods restart one by one.
+var (
+	retryAttempts  = 3
+	retryBaseDelay = 500 * time.Millisecond
+)
+
+// SetRetry sets how many times in total a gateway request is attempted and the delay before the first
+// retry, which doubles on every further retry. One attempt disables retries.
+func SetRetry(attempts int, baseDelay time.Duration) {
+	retryAttempts = max(attempts, 1)
+	retryBaseDelay = baseDelay
+}
+
+// Do sends req with the shared gateway client, retrying transient failures as configured with SetRetry.
+func Do(req *http.Request) (*http.Response, error) {
+	return DoWithRetry(insecureHTTPClient, req, retryAttempts, retryBaseDelay)
+}
+
+// DoWithRetry sends req up to attempts times, waiting baseDelay before the first retry and twice as long
+// before each further one. Connection errors and 5xx responses are retried; any other response,
+// including a 4xx, is returned as is. When every attempt failed the error says how many were made, and a
+// final 5xx is reported as a *StatusError. A request whose body can't be replayed is only sent once.
+func DoWithRetry(client *http.Client, req *http.Request, attempts int, baseDelay time.Duration) (*http.Response, error) {
+	if req.Body != nil && req.GetBody == nil {
+		attempts = 1
+	}
+	delay := baseDelay
+	for attempt := 1; ; attempt++ {
+		resp, err := client.Do(req)
+		if err == nil && resp.StatusCode < 500 {
+			return resp, nil
+		}
+		if attempt >= attempts || req.Context().Err() != nil {
+			if attempts == 1 {
+				return resp, err
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%w (after %d attempts)", err, attempt)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			statusErr := &StatusError{Endpoint: endpointName(req.URL.String()), StatusCode: resp.StatusCode, Status: resp.Status, Body: string(body)}
+			return nil, fmt.Errorf("%w (after %d attempts)", statusErr, attempt)
+		}
+
+		if err != nil {
+			Logger.Debugf(req.Context(), "HTTP %s %s failed, retrying in %s: %v", req.Method, req.URL, delay, err)
+		} else {
+			Logger.Debugf(req.Context(), "HTTP %s %s returned %s, retrying in %s", req.Method, req.URL, resp.Status, delay)
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		select {
+		case <-time.After(delay):
+		case <-req.Context().Done():
+			return nil, fmt.Errorf("%w (after %d attempts)", req.Context().Err(), attempt)
+		}
+		delay *= 2
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to replay request body: %w", err)
+			}
+			req.Body = body
+		}
+	}
+}
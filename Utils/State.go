@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// State is persisted between runs via --state-file so checks can compare the
+// cluster against what the previous run observed.
+type State struct {
+	LastRun time.Time     `json:"lastRun"`
+	Objects *ObjectCounts `json:"objects,omitempty"`
+	// Rebuilding tracks the disksets that were REBUILDING, keyed by diskset ID.
+	Rebuilding map[string]RebuildRecord `json:"rebuilding,omitempty"`
+	// Disksets is the number of disksets the gateway reported.
+	Disksets *int64 `json:"disksets,omitempty"`
+}
+
+// ObjectCounts records how many buckets and objects the gateway reported.
+type ObjectCounts struct {
+	Buckets int64 `json:"buckets"`
+	Objects int64 `json:"objects"`
+}
+
+// RebuildRecord records when a diskset was first seen REBUILDING and over how many consecutive runs.
+type RebuildRecord struct {
+	Since time.Time `json:"since"`
+	Runs  int       `json:"runs"`
+}
+
+// Merge records the fields a check observed, leaving the ones it didn't set untouched.
+func (s *State) Merge(observed *State) {
+	if observed.Objects != nil {
+		s.Objects = observed.Objects
+	}
+	if observed.Rebuilding != nil {
+		s.Rebuilding = observed.Rebuilding
+	}
+	if observed.Disksets != nil {
+		s.Disksets = observed.Disksets
+	}
+}
+
+// LoadState reads the state file at path. A missing file is not an error and
+// yields an empty state, since the first run has nothing to compare against.
+func LoadState(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &State{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file '%s': %w", path, err)
+	}
+
+	state := &State{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse state file '%s': %w", path, err)
+	}
+	return state, nil
+}
+
+// SaveState writes the state to path. The file is written to a temporary
+// sibling first and renamed into place so an interrupted run never leaves a
+// truncated state file behind.
+func SaveState(path string, state *State) error {
+	state.LastRun = time.Now()
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace state file '%s': %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"time"
+
+	Logger "Detective/Logger"
+)
+
+// SetTraceHTTP logs DNS, connect, TLS handshake and first-byte timings of every gateway request at
+// DEBUG level, to tell network, TLS and server-side slowness apart.
+func SetTraceHTTP(enabled bool) {
+	if enabled {
+		insecureHTTPClient.Transport = tracingTransport{base: insecureTransport}
+	} else {
+		insecureHTTPClient.Transport = insecureTransport
+	}
+}
+
+type tracingTransport struct {
+	base http.RoundTripper
+}
+
+func (t tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var start, dnsStart, connectStart, tlsStart time.Time
+	var dns, connect, handshake, firstByte time.Duration
+	trace := &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:              func(httptrace.DNSDoneInfo) { dns = time.Since(dnsStart) },
+		ConnectStart:         func(string, string) { connectStart = time.Now() },
+		ConnectDone:          func(string, string, error) { connect = time.Since(connectStart) },
+		TLSHandshakeStart:    func() { tlsStart = time.Now() },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { handshake = time.Since(tlsStart) },
+		GotFirstResponseByte: func() { firstByte = time.Since(start) },
+	}
+
+	start = time.Now()
+	resp, err := t.base.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+	if err != nil {
+		Logger.Debugf(req.Context(), "HTTP %s %s failed after %s: %v", req.Method, req.URL, time.Since(start), err)
+		return resp, err
+	}
+	Logger.Debugf(req.Context(), "HTTP %s %s -> %s: dns %s, connect %s, tls %s, first byte %s",
+		req.Method, req.URL, resp.Status, dns, connect, handshake, firstByte)
+	return resp, nil
+}
@@ -6,16 +6,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	Constants "Detective/Constants"
 
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
 )
 
 // Reuse a single insecure HTTP client across the process to avoid repeated
@@ -24,7 +29,17 @@ var insecureTransport = &http.Transport{
 	TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
 }
 
-var insecureHTTPClient = &http.Client{Transport: insecureTransport}
+// defaultHTTPTimeout bounds every gateway request, as a backstop for callers without a deadline of their
+// own.
+const defaultHTTPTimeout = 30 * time.Second
+
+var insecureHTTPClient = &http.Client{Transport: insecureTransport, Timeout: defaultHTTPTimeout}
+
+// SetVerifyTLS makes the shared gateway client verify the gateway's certificate instead of skipping
+// verification.
+func SetVerifyTLS(verify bool) {
+	insecureTransport.TLSClientConfig.InsecureSkipVerify = !verify
+}
 
 // GetInsecureHTTPClient returns a shared HTTP client configured to skip TLS
 // verification. Re-using this client reduces allocations and speeds up
@@ -33,6 +48,66 @@ func GetInsecureHTTPClient() *http.Client {
 	return insecureHTTPClient
 }
 
+// userAgent is the User-Agent sent with every gateway request, so gateway operators can tell the
+// health checker's traffic apart in their access logs.
+var userAgent = "ostore-health-check"
+
+// SetUserAgent replaces the User-Agent sent with every gateway request.
+func SetUserAgent(agent string) {
+	userAgent = agent
+}
+
+// ApplyUserAgent sets the configured User-Agent on req.
+func ApplyUserAgent(req *http.Request) {
+	req.Header.Set("User-Agent", userAgent)
+}
+
+// ReadTokenFile reads a token from path. The file is read on every call so a rotated short-lived token,
+// such as a projected service account token, is always picked up.
+func ReadTokenFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file '%s': %w", path, err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file '%s' is empty", path)
+	}
+	return token, nil
+}
+
+// DoAuthenticated sends a body-less request to the gateway with a token from auth set as the auth
+// header. When the gateway answers 401 and auth supplies a different token, the request is retried once
+// with it.
+func DoAuthenticated(req *http.Request, auth Authenticator) (*http.Response, error) {
+	return doAuthenticated(insecureHTTPClient, req, auth)
+}
+
+func doAuthenticated(client *http.Client, req *http.Request, auth Authenticator) (*http.Response, error) {
+	token, err := auth.Token(req.Context())
+	if err != nil {
+		return nil, &AuthError{Err: fmt.Errorf("failed to obtain a gateway token: %w", err)}
+	}
+	ApplyUserAgent(req)
+	req.Header.Set(authHeader(auth, token))
+	resp, err := DoWithRetry(client, req, retryAttempts, retryBaseDelay)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	fresh, err := freshToken(req.Context(), auth, token)
+	if err != nil {
+		resp.Body.Close()
+		return nil, fmt.Errorf("gateway returned 401 and the token could not be refreshed: %w", err)
+	}
+	if fresh == token {
+		return resp, nil
+	}
+	resp.Body.Close()
+	req.Header.Set(authHeader(auth, fresh))
+	return DoWithRetry(client, req, retryAttempts, retryBaseDelay)
+}
+
 // ParseJSON unmarshals raw JSON bytes into an interface{} and avoids an
 // intermediate string/[]byte conversion that was present across callers.
 func ParseJSON(data []byte) (interface{}, error) {
@@ -55,15 +130,49 @@ func ParseJSONString(jsonString string) (interface{}, error) {
 	return result, nil
 }
 
-func FindHelmReleaseByChart(kubeconfigPath, targetChartVersion string) (string, string, error) {
-	actionConfig := new(action.Configuration)
-	configFlags := genericclioptions.NewConfigFlags(true) // 'true' uses persistent flags
+// BuildKubeConfig loads the kubeconfig from source, using kubeContext instead of the current context
+// when it is set. An in-cluster source uses the pod's service account.
+func BuildKubeConfig(source KubeconfigSource, kubeContext string) (*rest.Config, error) {
+	if source.InCluster {
+		if kubeContext != "" {
+			return nil, fmt.Errorf("cannot select context '%s' when running in-cluster", kubeContext)
+		}
+		return rest.InClusterConfig()
+	}
+	if source.Data != nil && kubeContext == "" {
+		return clientcmd.RESTConfigFromKubeConfig(source.Data)
+	}
+	clientConfig, err := source.clientConfig(kubeContext)
+	if err != nil {
+		return nil, err
+	}
+	return clientConfig.ClientConfig()
+}
 
-	// Set the kubeconfig path directly on the flags object.
-	configFlags.KubeConfig = &kubeconfigPath
-	err := actionConfig.Init(configFlags, "", os.Getenv("HELM_DRIVER"), log.Printf)
+// ResolveKubeContext returns the name of the context BuildKubeConfig uses: kubeContext when set,
+// otherwise the kubeconfig's current context.
+func ResolveKubeContext(source KubeconfigSource, kubeContext string) (string, error) {
+	if kubeContext != "" {
+		return kubeContext, nil
+	}
+	if source.InCluster {
+		return inClusterContext, nil
+	}
+	raw, err := source.Load()
 	if err != nil {
-		return "", "", fmt.Errorf("failed to initialize Helm action config: %w", err)
+		return "", fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	return raw.CurrentContext, nil
+}
+
+// FindHelmReleaseByChart returns the deployed release whose chart the selector matches, searching the
+// cluster config points at. When several releases match, the error lists them rather than one being
+// picked at random.
+func FindHelmReleaseByChart(config *rest.Config, chart ChartSelector) (*release.Release, error) {
+	actionConfig := new(action.Configuration)
+	err := actionConfig.Init(restClientGetter{config: config}, "", os.Getenv("HELM_DRIVER"), log.Printf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Helm action config: %w", err)
 	}
 
 	listAction := action.NewList(actionConfig)
@@ -72,82 +181,91 @@ func FindHelmReleaseByChart(kubeconfigPath, targetChartVersion string) (string,
 
 	releases, err := listAction.Run()
 	if err != nil {
-		return "", "", fmt.Errorf("failed to run 'helm list' action: %w", err)
+		return nil, fmt.Errorf("failed to run 'helm list' action: %w", err)
 	}
 
 	if len(releases) == 0 {
-		return "", "", fmt.Errorf("no deployed Helm releases found in any namespace")
+		return nil, fmt.Errorf("no deployed Helm releases found in any namespace")
 	}
 
+	matches := []*release.Release{}
+	candidates := []string{}
 	for _, rel := range releases {
-		chartNameWithVersion := fmt.Sprintf("%s-%s", rel.Chart.Name(), rel.Chart.Metadata.Version)
-
-		if chartNameWithVersion == targetChartVersion {
-			log.Printf("✅ Release Name: '%s', Namespace: '%s'", rel.Name, rel.Namespace)
-			return rel.Name, rel.Namespace, nil
+		if chart.Matches(rel.Chart.Name(), rel.Chart.Metadata.Version) {
+			matches = append(matches, rel)
+			candidates = append(candidates, fmt.Sprintf("'%s' in namespace '%s' (%s-%s)", rel.Name, rel.Namespace, rel.Chart.Name(), rel.Chart.Metadata.Version))
 		}
 	}
 
-	return "", "", fmt.Errorf("❌ no deployed release found for chart '%s'", targetChartVersion)
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf(Constants.SymbolFail+" no deployed release found for chart '%s'", chart)
+	case 1:
+		rel := matches[0]
+		log.Printf(Constants.SymbolOK+" Release Name: '%s', Namespace: '%s', Chart: %s-%s", rel.Name, rel.Namespace, rel.Chart.Name(), rel.Chart.Metadata.Version)
+		return rel, nil
+	}
+	return nil, fmt.Errorf(Constants.SymbolFail+" %d deployed releases match chart '%s', narrow it down with --chart: %s", len(matches), chart, strings.Join(candidates, ", "))
 }
 
-func TriggerPostRequestAndGetToken(serviceIP string) (string, error) {
-	url := "https://" + serviceIP + ":9001/user"
-	jsonData := `{"password":"Robin123","username":"robin"}`
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-	}
-	client := &http.Client{Transport: tr}
+// TriggerPostRequestAndGetToken logs in to the gateway with the given credentials and returns the
+// token it issues.
+func TriggerPostRequestAndGetToken(serviceIP, username, password string) (string, error) {
+	return PasswordAuthenticator{ServiceIP: serviceIP, Username: username, Password: password}.Token(context.Background())
+}
 
-	req, err := http.NewRequest("POST", url, strings.NewReader(jsonData))
+// GetExternalIPForService returns the external address of the service, from its LoadBalancer ingress
+// entries and its ExternalIPs. When there are several, the first reachable on the admin port is used,
+// falling back to the first one when none is.
+func GetExternalIPForService(ctx context.Context, clientset *kubernetes.Clientset, namespace, serviceName string) (string, error) {
+	// Get the service object from the cluster
+	service, err := clientset.CoreV1().Services(namespace).Get(ctx, serviceName, metav1.GetOptions{})
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return "", fmt.Errorf(Constants.SymbolFail+" failed to get service '%s' in namespace '%s': %w", serviceName, namespace, err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-rakuten-internal", "user")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to execute request: %w", err)
+	candidates := append(LoadBalancerEndpoints(service), service.Spec.ExternalIPs...)
+	if len(candidates) == 0 {
+		return "", fmt.Errorf(Constants.SymbolFail+" no external IP found for service '%s' (it might be <pending> or not exposed)", serviceName)
 	}
-	defer resp.Body.Close()
-	token := resp.Header.Get("X-Rakuten-Token")
-	if token == "" {
-		return "", fmt.Errorf("header 'X-Rakuten-Token' not found in the response")
+	if len(candidates) == 1 {
+		log.Print(Constants.SymbolOK + " Found external address for service '" + serviceName + "': " + candidates[0] + Constants.TwoNewLines)
+		return candidates[0], nil
 	}
 
-	return token, nil
-}
-
-// It checks both the LoadBalancer Ingress status and the ExternalIPs spec field.
-func GetExternalIPForService(clientset *kubernetes.Clientset, namespace, serviceName string) (string, error) {
-	// log.Printf("🔎 Attempting to get service '%s' in namespace '%s'...", serviceName, namespace)
-
-	// Get the service object from the cluster
-	service, err := clientset.CoreV1().Services(namespace).Get(context.TODO(), serviceName, metav1.GetOptions{})
-	if err != nil {
-		return "", fmt.Errorf("❌ failed to get service '%s' in namespace '%s': %w", serviceName, namespace, err)
+	// With several addresses published, prefer one that can actually be reached from here.
+	port := AdminPort()
+	for _, candidate := range candidates {
+		if IsReachable(candidate, port, 3*time.Second) {
+			log.Printf(Constants.SymbolOK+" Using external address %s of service '%s': reachable on port %s (candidates: %s)%s", candidate, serviceName, port, strings.Join(candidates, ", "), Constants.TwoNewLines)
+			return candidate, nil
+		}
+		log.Printf(Constants.SymbolWarn+" External address %s of service '%s' is not reachable on port %s", candidate, serviceName, port)
 	}
+	log.Printf(Constants.SymbolWarn+" None of the external addresses of service '%s' are reachable on port %s, tried %s; falling back to %s%s", serviceName, port, strings.Join(candidates, ", "), candidates[0], Constants.TwoNewLines)
+	return candidates[0], nil
+}
 
-	// log.Printf("✅ Successfully retrieved service '%s'. Checking for external IP.", serviceName)
-	if len(service.Status.LoadBalancer.Ingress) > 0 {
-		ingress := service.Status.LoadBalancer.Ingress[0]
+// LoadBalancerEndpoints returns every address published in the service's LoadBalancer ingress status,
+// preferring the IP of each entry and falling back to its hostname.
+func LoadBalancerEndpoints(service *v1.Service) []string {
+	endpoints := []string{}
+	for _, ingress := range service.Status.LoadBalancer.Ingress {
 		if ingress.IP != "" {
-			log.Printf("✅ Found IP in LoadBalancer Ingress status: %s", ingress.IP)
-			return ingress.IP, nil
-		}
-		// Fallback to hostname if IP is not available
-		if ingress.Hostname != "" {
-			log.Printf("✅ Found Hostname in LoadBalancer Ingress status: %s", ingress.Hostname)
-			return ingress.Hostname, nil
+			endpoints = append(endpoints, ingress.IP)
+		} else if ingress.Hostname != "" {
+			endpoints = append(endpoints, ingress.Hostname)
 		}
 	}
+	return endpoints
+}
 
-	if len(service.Spec.ExternalIPs) > 0 {
-		ip := service.Spec.ExternalIPs[0]
-		log.Print("✅ Found IP in External IPs spec: " + ip + Constants.TwoNewLines)
-		return ip, nil
+// IsReachable reports whether a TCP connection to host:port can be opened within the timeout.
+func IsReachable(host, port string, timeout time.Duration) bool {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, port), timeout)
+	if err != nil {
+		return false
 	}
-	return "", fmt.Errorf("❌ no external IP found for service '%s' (it might be <pending> or not exposed)", serviceName)
+	conn.Close()
+	return true
 }
@@ -0,0 +1,35 @@
+package utils
+
+import "regexp"
+
+// secretKeyPattern matches value keys that commonly hold credentials.
+var secretKeyPattern = regexp.MustCompile(`(?i)pass(word)?|secret|token|credential|private|api_?key|access_?key`)
+
+// RedactValues returns a copy of Helm values with every value under a credential-like key replaced, so
+// the effective configuration can be shared without leaking secrets.
+func RedactValues(values map[string]interface{}) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		if secretKeyPattern.MatchString(key) {
+			redacted[key] = "<redacted>"
+			continue
+		}
+		redacted[key] = redactValue(value)
+	}
+	return redacted
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return RedactValues(v)
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = redactValue(item)
+		}
+		return items
+	default:
+		return value
+	}
+}
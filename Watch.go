@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	Check "Detective/Checks"
+	Constants "Detective/Constants"
+)
+
+// latencyStats accumulates every check's duration across the runs of a watch session, so a subsystem
+// whose response time creeps up stands out even while it stays healthy.
+type latencyStats struct {
+	since     time.Time
+	runs      int
+	durations map[string][]time.Duration
+	// order keeps the checks in the order they were first seen.
+	order []string
+}
+
+func newLatencyStats() *latencyStats {
+	return &latencyStats{since: time.Now(), durations: map[string][]time.Duration{}}
+}
+
+func (s *latencyStats) record(results []Check.CheckResult) {
+	s.runs++
+	for _, result := range results {
+		if outcome := result.Outcome(); outcome == Check.StatusSkip || outcome == Check.StatusCancelled {
+			continue
+		}
+		if _, seen := s.durations[result.Name]; !seen {
+			s.order = append(s.order, result.Name)
+		}
+		s.durations[result.Name] = append(s.durations[result.Name], result.Duration)
+	}
+}
+
+// print writes min, median, p95 and max of every check's duration.
+func (s *latencyStats) print(w io.Writer) {
+	var b strings.Builder
+	b.WriteString(Constants.BoldGreen + fmt.Sprintf("Check durations over %d runs since %s", s.runs, s.since.Format(time.DateTime)) + Constants.Reset + Constants.Newline + Constants.Differentiator + Constants.Newline)
+	fmt.Fprintf(&b, "%-28s %6s %10s %10s %10s %10s\n", "CHECK", "RUNS", "MIN", "MEDIAN", "P95", "MAX")
+	for _, name := range s.order {
+		durations := append([]time.Duration{}, s.durations[name]...)
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		fmt.Fprintf(&b, "%-28s %6d %10s %10s %10s %10s\n", name, len(durations),
+			durations[0].Round(time.Millisecond), percentile(durations, 50).Round(time.Millisecond),
+			percentile(durations, 95).Round(time.Millisecond), durations[len(durations)-1].Round(time.Millisecond))
+	}
+	b.WriteString(Constants.Newline)
+	fmt.Fprint(w, b.String())
+}
+
+// percentile returns the nearest-rank percentile p of sorted, which must not be empty.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
+
+// watch calls runOnce every --interval until interrupted, keeping the duration statistics of every run.
+// SIGUSR1 (where available) prints the statistics gathered so far and starts them afresh. SIGINT or
+// SIGTERM cancels the run in flight, prints the statistics including what that run finished and returns
+// exitInterrupted.
+func watch(cfg Config, runOnce func(ctx context.Context) []Check.CheckResult, stdout io.Writer) int {
+	stats := newLatencyStats()
+	reset := make(chan os.Signal, 1)
+	if len(resetSignals) > 0 {
+		signal.Notify(reset, resetSignals...)
+	}
+	ctx, stop := interruptContext()
+	defer stop()
+
+	for {
+		stats.record(runOnce(ctx))
+		if ctx.Err() != nil {
+			stats.print(stdout)
+			return exitInterrupted
+		}
+		log.Printf("Next run in %s", cfg.WatchInterval)
+		next := time.After(cfg.WatchInterval)
+	wait:
+		for {
+			select {
+			case <-next:
+				break wait
+			case <-reset:
+				stats.print(stdout)
+				stats = newLatencyStats()
+				log.Print("Check duration statistics reset")
+			case <-ctx.Done():
+				stats.print(stdout)
+				return exitInterrupted
+			}
+		}
+	}
+}